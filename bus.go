@@ -0,0 +1,205 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+package mason
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Overflow policies of a Subscription buffer.
+const (
+	// OverflowBlock blocks publishing until the subscriber catches up.
+	OverflowBlock Overflow = iota
+	// OverflowDropOldest discards the oldest buffered Event to make room.
+	OverflowDropOldest
+	// OverflowDropNewest discards the Event being published.
+	OverflowDropNewest
+)
+
+type (
+	// Overflow is the policy applied when a Subscription buffer is full.
+	Overflow int
+	// SubscriberOption is a functional option for a Subscription.
+	SubscriberOption func(sub *Subscription)
+	// Subscription is an Event subscriber of a Scaffold.
+	Subscription struct {
+		scaffold *Scaffold
+		fn       func(Event)
+		mu       sync.Mutex
+		queue    chan Event
+		overflow Overflow
+		dropped  atomic.Uint64
+		done     chan struct{}
+		once     sync.Once
+	}
+)
+
+// Buffered buffers up to n Event(s) for a Subscription, which are delivered asynchronously in publishing order.
+// The overflow policy decides what happens once the buffer is full. Unbuffered Subscription(s) are delivered
+// synchronously, blocking publishing, unless a drop policy is set in which case a buffer of one is used.
+func Buffered(n int, overflow Overflow) SubscriberOption {
+	return func(sub *Subscription) {
+		if n < 0 {
+			n = 0
+		}
+		if n == 0 && overflow != OverflowBlock {
+			n = 1
+		}
+		sub.overflow = overflow
+		if n > 0 {
+			sub.queue = make(chan Event, n)
+		}
+	}
+}
+
+// Subscribe subscribes a channel to Event(s). The channel is not closed on Unsubscribe.
+func (s *Scaffold) Subscribe(ch chan<- Event, opt ...SubscriberOption) *Subscription {
+	sub := s.subscription(opt...)
+	sub.fn = func(e Event) {
+		select {
+		case ch <- e:
+		case <-sub.done:
+		}
+	}
+	return s.subscribe(sub)
+}
+
+// SubscribeFunc subscribes a callback to Event(s). Callbacks of a Subscription are never called concurrently.
+func (s *Scaffold) SubscribeFunc(fn func(Event), opt ...SubscriberOption) *Subscription {
+	sub := s.subscription(opt...)
+	sub.fn = fn
+	return s.subscribe(sub)
+}
+
+// subscription creates a Subscription without a callback, which must be set before it is subscribed.
+func (s *Scaffold) subscription(opt ...SubscriberOption) *Subscription {
+	sub := &Subscription{scaffold: s, done: make(chan struct{})}
+	for _, o := range opt {
+		o(sub)
+	}
+	return sub
+}
+
+// subscribe starts delivering Event(s) to a Subscription.
+func (s *Scaffold) subscribe(sub *Subscription) *Subscription {
+	if sub.queue != nil {
+		go sub.run()
+	}
+	s.subsMu.Lock()
+	s.subs = append(s.subs, sub)
+	s.subsMu.Unlock()
+	return sub
+}
+
+// Unsubscribe stops delivering Event(s) to the subscriber. Buffered Event(s) not yet delivered are discarded.
+func (sub *Subscription) Unsubscribe() {
+	sub.once.Do(func() {
+		s := sub.scaffold
+		s.subsMu.Lock()
+		for i, other := range s.subs {
+			if other == sub {
+				s.subs = append(s.subs[:i:i], s.subs[i+1:]...)
+				break
+			}
+		}
+		s.subsMu.Unlock()
+		close(sub.done)
+	})
+}
+
+// Dropped returns the number of Event(s) discarded by the overflow policy.
+func (sub *Subscription) Dropped() uint64 {
+	return sub.dropped.Load()
+}
+
+// run delivers buffered Event(s) until Unsubscribe.
+func (sub *Subscription) run() {
+	for {
+		select {
+		case e := <-sub.queue:
+			sub.deliver(e)
+		case <-sub.done:
+			return
+		}
+	}
+}
+
+// deliver calls the subscriber unless it has unsubscribed.
+func (sub *Subscription) deliver(e Event) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	select {
+	case <-sub.done:
+		return
+	default:
+	}
+	sub.fn(e)
+}
+
+// send publishes an Event to the subscriber according to its overflow policy.
+func (sub *Subscription) send(e Event) {
+	if sub.queue == nil {
+		sub.deliver(e)
+		return
+	}
+	switch sub.overflow {
+	case OverflowDropNewest:
+		select {
+		case sub.queue <- e:
+		default:
+			sub.dropped.Add(1)
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case sub.queue <- e:
+				return
+			default:
+			}
+			select {
+			case <-sub.queue:
+				sub.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case sub.queue <- e:
+		case <-sub.done:
+		}
+	}
+}
+
+// publish is a publisher utility for Event(s).
+func (s *Scaffold) publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	s.subsMu.RLock()
+	subs := s.subs
+	s.subsMu.RUnlock()
+	for _, sub := range subs {
+		sub.send(e)
+	}
+}
@@ -0,0 +1,299 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+// Package climortar builds a command-line subcommand tree from command Stone hooked by mason.Module(s), on top of the
+// standard flag package. Help text lists the Module providing each command, and conflicting commands are reported at
+// hook time.
+package climortar
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/pedregon/mason/v2"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+var (
+	ErrInvalidCommand     error = errors.New("invalid command")
+	ErrConflictingCommand error = errors.New("conflicting command")
+	ErrUnknownCommand     error = errors.New("unknown command")
+)
+
+var (
+	// interface guard.
+	_ mason.Mortar       = (*Mortar)(nil)
+	_ mason.ModuleHooker = (*Mortar)(nil)
+	_ mason.Unhooker     = (*Mortar)(nil)
+)
+
+type (
+	// Command is a Stone that mounts a subcommand.
+	Command struct {
+		// Name is the command path, where nested commands are separated by spaces, such as "db migrate". Parent
+		// commands that are not hooked themselves are implied.
+		Name string
+		// Usage is a one-line description.
+		Usage string
+		// Flags are parsed from the arguments following the command path. Its output and usage are overridden by
+		// Mortar. A new flag.FlagSet is used if nil.
+		Flags *flag.FlagSet
+		// Run runs the command with the remaining arguments after flags. A Command without Run lists its
+		// subcommands.
+		Run func(ctx context.Context, args []string) error
+	}
+	// Option is a functional option for Mortar.
+	Option func(m *Mortar)
+	// Mortar is a mason.Mortar that dispatches command-line arguments to *Command Stone.
+	Mortar struct {
+		name   string
+		output io.Writer
+		mu     sync.RWMutex
+		root   *node
+	}
+	// node is a command in the tree.
+	node struct {
+		path     []string
+		cmd      *Command
+		module   mason.Info
+		children map[string]*node
+	}
+)
+
+// Output overrides where help text and flag errors are written, which is os.Stderr by default.
+func Output(w io.Writer) Option {
+	return func(m *Mortar) {
+		m.output = w
+	}
+}
+
+// New creates a Mortar for a program name.
+func New(name string, opt ...Option) *Mortar {
+	m := &Mortar{name: name, output: os.Stderr, root: newNode(nil)}
+	for _, fn := range opt {
+		fn(m)
+	}
+	return m
+}
+
+// newNode creates a command tree node.
+func newNode(path []string) *node {
+	return &node{path: path, children: make(map[string]*node)}
+}
+
+// Hook implements mason.Mortar for Command(s) that were not provided by a Module.
+func (m *Mortar) Hook(stone ...mason.Stone) error {
+	return m.HookModule(mason.Info{}, stone...)
+}
+
+// HookModule implements mason.ModuleHooker. Every Stone must be a *Command, and a command path may only be hooked
+// once.
+func (m *Mortar) HookModule(info mason.Info, stone ...mason.Stone) error {
+	for i, s := range stone {
+		cmd, ok := s.(*Command)
+		if !ok {
+			_ = m.Unhook(stone[:i]...)
+			return fmt.Errorf("%w %T from %s", mason.ErrUnhandledStone, s, info)
+		}
+		if err := m.mount(info, cmd); err != nil {
+			_ = m.Unhook(stone[:i]...)
+			return err
+		}
+	}
+	return nil
+}
+
+// mount mounts a Command provided by a Module.
+func (m *Mortar) mount(info mason.Info, cmd *Command) error {
+	path := strings.Fields(cmd.Name)
+	if len(path) == 0 {
+		return fmt.Errorf("%w '%s' from %s", ErrInvalidCommand, cmd.Name, info)
+	}
+	for _, name := range path {
+		if strings.HasPrefix(name, "-") {
+			return fmt.Errorf("%w '%s' from %s", ErrInvalidCommand, cmd.Name, info)
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := m.root
+	for i, name := range path {
+		child, ok := n.children[name]
+		if !ok {
+			child = newNode(path[:i+1])
+			n.children[name] = child
+		}
+		n = child
+	}
+	if n.cmd != nil {
+		return fmt.Errorf("%w '%s' from %s, already hooked by %s", ErrConflictingCommand, strings.Join(path, " "),
+			info, n.module)
+	}
+	if cmd.Flags == nil {
+		cmd.Flags = flag.NewFlagSet(strings.Join(path, " "), flag.ContinueOnError)
+	}
+	cmd.Flags.SetOutput(m.output)
+	cmd.Flags.Usage = func() {
+		m.help(n)
+	}
+	n.cmd, n.module = cmd, info
+	return nil
+}
+
+// Unhook implements mason.Unhooker, removing implied parent commands that are left without subcommands.
+func (m *Mortar) Unhook(stone ...mason.Stone) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range stone {
+		cmd, ok := s.(*Command)
+		if !ok {
+			continue
+		}
+		m.root.remove(cmd, strings.Fields(cmd.Name))
+	}
+	return nil
+}
+
+// remove removes a Command from the subtree, reporting whether the node is left empty.
+func (n *node) remove(cmd *Command, path []string) bool {
+	if len(path) == 0 {
+		if n.cmd == cmd {
+			n.cmd, n.module = nil, mason.Info{}
+		}
+	} else if child, ok := n.children[path[0]]; ok && child.remove(cmd, path[1:]) {
+		delete(n.children, path[0])
+	}
+	return n.cmd == nil && len(n.children) == 0
+}
+
+// Main dispatches the command-line arguments of the process, os.Args.
+func (m *Mortar) Main(ctx context.Context) error {
+	return m.Run(ctx, os.Args[1:])
+}
+
+// Run dispatches arguments, excluding the program name, to the deepest matching Command. Help is written for
+// Command(s) without Run, or when requested with -h or -help, in which case flag.ErrHelp is returned.
+// ErrUnknownCommand is returned for an unknown subcommand of a Command without Run.
+func (m *Mortar) Run(ctx context.Context, args []string) error {
+	m.mu.RLock()
+	n := m.root
+	for len(args) > 0 {
+		child, ok := n.children[args[0]]
+		if !ok {
+			break
+		}
+		n, args = child, args[1:]
+	}
+	cmd := n.cmd
+	m.mu.RUnlock()
+	if cmd == nil || cmd.Run == nil {
+		m.help(n)
+		if len(args) > 0 && !isHelp(args[0]) {
+			return fmt.Errorf("%w '%s'", ErrUnknownCommand, strings.Join(append(n.path, args[0]), " "))
+		}
+		return flag.ErrHelp
+	}
+	if err := cmd.Flags.Parse(args); err != nil {
+		return err
+	}
+	return cmd.Run(ctx, cmd.Flags.Args())
+}
+
+// Help writes the help text of a command path, or of the program if empty.
+func (m *Mortar) Help(path ...string) {
+	m.mu.RLock()
+	n := m.root
+	for _, name := range path {
+		child, ok := n.children[name]
+		if !ok {
+			break
+		}
+		n = child
+	}
+	m.mu.RUnlock()
+	m.help(n)
+}
+
+// help writes the help text of a node.
+func (m *Mortar) help(n *node) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	usage := strings.Join(append([]string{m.name}, n.path...), " ")
+	if len(n.children) > 0 {
+		usage += " <command>"
+	}
+	if n.cmd != nil && n.cmd.Run != nil {
+		usage += " [flags] [arguments]"
+	}
+	fmt.Fprintf(m.output, "Usage: %s\n", usage)
+	if n.cmd != nil {
+		if n.cmd.Usage != "" {
+			fmt.Fprintf(m.output, "\n%s\n", n.cmd.Usage)
+		}
+		if n.module != (mason.Info{}) {
+			fmt.Fprintf(m.output, "\nProvided by %s.\n", n.module)
+		}
+	}
+	if len(n.children) > 0 {
+		names := make([]string, 0, len(n.children))
+		for name := range n.children {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(m.output, "\nCommands:\n")
+		tw := tabwriter.NewWriter(m.output, 0, 4, 2, ' ', 0)
+		for _, name := range names {
+			child := n.children[name]
+			var desc string
+			if child.cmd != nil {
+				desc = child.cmd.Usage
+				if child.module != (mason.Info{}) {
+					desc = strings.TrimSpace(desc + " (" + child.module.String() + ")")
+				}
+			}
+			if desc == "" {
+				fmt.Fprintf(tw, "  %s\n", name)
+				continue
+			}
+			fmt.Fprintf(tw, "  %s\t%s\n", name, desc)
+		}
+		_ = tw.Flush()
+	}
+	if n.cmd != nil && n.cmd.Run != nil {
+		fmt.Fprintf(m.output, "\nFlags:\n")
+		n.cmd.Flags.PrintDefaults()
+	}
+}
+
+// isHelp checks whether an argument requests help.
+func isHelp(arg string) bool {
+	switch arg {
+	case "-h", "-help", "--help", "help":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+package climortar_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"github.com/pedregon/mason/v2"
+	"github.com/pedregon/mason/v2/climortar"
+	"strings"
+	"testing"
+)
+
+var (
+	_ mason.Module = (*module)(nil)
+)
+
+type (
+	module struct {
+		name     string
+		commands []mason.Stone
+	}
+)
+
+func (mod module) Info() mason.Info {
+	return mason.Info{Name: mod.name, Version: "1.0.0"}
+}
+
+func (mod module) Provision(c *mason.Context) error {
+	return c.Hook(mod.commands...)
+}
+
+func TestMortar(t *testing.T) {
+	var (
+		dryRun bool
+		ran    []string
+	)
+	migrate := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	migrate.BoolVar(&dryRun, "dry-run", false, "print without applying")
+	db := module{name: "db", commands: []mason.Stone{
+		&climortar.Command{Name: "db", Usage: "Manage the database"},
+		&climortar.Command{Name: "db migrate", Usage: "Apply migrations", Flags: migrate,
+			Run: func(_ context.Context, args []string) error {
+				ran = append(ran, "migrate "+strings.Join(args, " "))
+				return nil
+			},
+		},
+	}}
+	server := module{name: "server", commands: []mason.Stone{
+		&climortar.Command{Name: "serve", Usage: "Serve HTTP", Run: func(_ context.Context, _ []string) error {
+			ran = append(ran, "serve")
+			return nil
+		}},
+	}}
+	var out bytes.Buffer
+	mort := climortar.New("app", climortar.Output(&out))
+	scaffold := mason.New(mort)
+	if err := scaffold.Load(context.TODO(), db, server); err != nil {
+		t.Fatal(err)
+	}
+	// dispatch
+	if err := mort.Run(context.TODO(), []string{"db", "migrate", "-dry-run", "v2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mort.Run(context.TODO(), []string{"serve"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ran, ", ") != "migrate v2, serve" || !dryRun {
+		t.Fatal(ran, dryRun)
+	}
+	// help
+	if err := mort.Run(context.TODO(), nil); !errors.Is(err, flag.ErrHelp) {
+		t.Fatal(err)
+	}
+	expected := "Usage: app <command>\n\nCommands:\n" +
+		"  db     Manage the database (db-1.0.0)\n" +
+		"  serve  Serve HTTP (server-1.0.0)\n"
+	if out.String() != expected {
+		t.Fatal(out.String())
+	}
+	out.Reset()
+	if err := mort.Run(context.TODO(), []string{"db", "migrate", "-h"}); !errors.Is(err, flag.ErrHelp) {
+		t.Fatal(err)
+	}
+	if help := out.String(); !strings.Contains(help, "Usage: app db migrate [flags] [arguments]") ||
+		!strings.Contains(help, "Provided by db-1.0.0.") || !strings.Contains(help, "-dry-run") {
+		t.Fatal(help)
+	}
+	if err := mort.Run(context.TODO(), []string{"db", "seed"}); !errors.Is(err, climortar.ErrUnknownCommand) {
+		t.Fatal(err)
+	}
+	// unload
+	if err := scaffold.Unload(context.TODO(), db.Info(), false); err != nil {
+		t.Fatal(err)
+	}
+	if err := mort.Run(context.TODO(), []string{"db"}); !errors.Is(err, climortar.ErrUnknownCommand) {
+		t.Fatal(err)
+	}
+}
+
+func TestMortar_Conflict(t *testing.T) {
+	foo := module{name: "foo", commands: []mason.Stone{&climortar.Command{Name: "run"}}}
+	bar := module{name: "bar", commands: []mason.Stone{&climortar.Command{Name: " run "}}}
+	scaffold := mason.New(climortar.New("app"))
+	if err := scaffold.Load(context.TODO(), foo); err != nil {
+		t.Fatal(err)
+	}
+	err := scaffold.Load(context.TODO(), bar)
+	if !errors.Is(err, climortar.ErrConflictingCommand) || !strings.Contains(err.Error(), "'run' from bar-1.0.0, already hooked by foo-1.0.0") {
+		t.Fatal(err)
+	}
+	baz := module{name: "baz", commands: []mason.Stone{&climortar.Command{Name: "-x"}}}
+	if err = scaffold.Load(context.TODO(), baz); !errors.Is(err, climortar.ErrInvalidCommand) {
+		t.Fatal(err)
+	}
+}
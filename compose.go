@@ -0,0 +1,230 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+package mason
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// interface guard.
+	_ ModuleHooker = (*chained)(nil)
+	_ Unhooker     = (*chained)(nil)
+	_ ModuleHooker = (*fanOut)(nil)
+	_ Unhooker     = (*fanOut)(nil)
+	_ ModuleHooker = (*firstAccepting)(nil)
+	_ Unhooker     = (*firstAccepting)(nil)
+)
+
+type (
+	// HookFunc hooks Stone provided by a Module, such as ModuleHooker.HookModule.
+	HookFunc func(info Info, stone ...Stone) error
+	// Middleware wraps a HookFunc, such as for validation, logging, metrics, or permission checks.
+	Middleware func(next HookFunc) HookFunc
+	// chained is a Mortar wrapped by Middleware.
+	chained struct {
+		mort Mortar
+		hook HookFunc
+	}
+	// fanOut is a Mortar that hooks every Stone to every Mortar.
+	fanOut struct {
+		morts []Mortar
+	}
+	// firstAccepting is a Mortar that hooks each Stone to the first Mortar accepting it.
+	firstAccepting struct {
+		morts    []Mortar
+		mu       sync.Mutex
+		accepted []accepted
+	}
+	// accepted is a Stone hooked by firstAccepting.
+	accepted struct {
+		stone Stone
+		mort  Mortar
+	}
+	// composite is implemented by Mortar(s) composed of other Mortar(s).
+	composite interface {
+		mortars() []Mortar
+	}
+)
+
+// Chain wraps a Mortar with Middleware, such that the first Middleware is the outermost. Unhook bypasses the
+// Middleware.
+func Chain(middleware ...Middleware) func(mort Mortar) Mortar {
+	return func(mort Mortar) Mortar {
+		hook := hookFunc(mort)
+		for i := len(middleware) - 1; i >= 0; i-- {
+			hook = middleware[i](hook)
+		}
+		return &chained{mort: mort, hook: hook}
+	}
+}
+
+// FanOut combines Mortar(s) such that every Stone is hooked to every Mortar, in order. If any Mortar fails, the
+// Stone are unhooked from the preceding Mortar(s) that implement Unhooker.
+func FanOut(mort ...Mortar) Mortar {
+	return &fanOut{morts: mort}
+}
+
+// FirstAccepting combines Mortar(s) such that each Stone is hooked to the first Mortar that accepts it, in order.
+// A Mortar declines a Stone by failing with ErrUnhandledStone; any other failure is returned as is.
+func FirstAccepting(mort ...Mortar) Mortar {
+	return &firstAccepting{morts: mort}
+}
+
+// hookFunc adapts a Mortar to a HookFunc, preferring ModuleHooker.
+func hookFunc(mort Mortar) HookFunc {
+	if hooker, ok := mort.(ModuleHooker); ok {
+		return hooker.HookModule
+	}
+	return func(_ Info, stone ...Stone) error {
+		return mort.Hook(stone...)
+	}
+}
+
+// unhookFunc unhooks Stone from a Mortar, which is a no-op unless it implements Unhooker.
+func unhookFunc(mort Mortar, stone ...Stone) error {
+	if unhooker, ok := mort.(Unhooker); ok && len(stone) > 0 {
+		return unhooker.Unhook(stone...)
+	}
+	return nil
+}
+
+// Hook implements Mortar.
+func (m *chained) Hook(stone ...Stone) error {
+	return m.hook(Info{}, stone...)
+}
+
+// HookModule implements ModuleHooker.
+func (m *chained) HookModule(info Info, stone ...Stone) error {
+	return m.hook(info, stone...)
+}
+
+// Unhook implements Unhooker.
+func (m *chained) Unhook(stone ...Stone) error {
+	return unhookFunc(m.mort, stone...)
+}
+
+// mortars implements composite.
+func (m *chained) mortars() []Mortar {
+	return []Mortar{m.mort}
+}
+
+// Hook implements Mortar.
+func (m *fanOut) Hook(stone ...Stone) error {
+	return m.HookModule(Info{}, stone...)
+}
+
+// HookModule implements ModuleHooker.
+func (m *fanOut) HookModule(info Info, stone ...Stone) error {
+	for i, mort := range m.morts {
+		if err := hookFunc(mort)(info, stone...); err != nil {
+			errs := []error{err}
+			for j := i - 1; j >= 0; j-- {
+				errs = append(errs, unhookFunc(m.morts[j], stone...))
+			}
+			return errors.Join(errs...)
+		}
+	}
+	return nil
+}
+
+// Unhook implements Unhooker.
+func (m *fanOut) Unhook(stone ...Stone) error {
+	var errs []error
+	for i := len(m.morts) - 1; i >= 0; i-- {
+		errs = append(errs, unhookFunc(m.morts[i], stone...))
+	}
+	return errors.Join(errs...)
+}
+
+// mortars implements composite.
+func (m *fanOut) mortars() []Mortar {
+	return m.morts
+}
+
+// Hook implements Mortar.
+func (m *firstAccepting) Hook(stone ...Stone) error {
+	return m.HookModule(Info{}, stone...)
+}
+
+// HookModule implements ModuleHooker. If a Stone fails, the preceding Stone are unhooked, like FanOut.
+func (m *firstAccepting) HookModule(info Info, stone ...Stone) error {
+	for i, s := range stone {
+		var hooked bool
+		for _, mort := range m.morts {
+			err := hookFunc(mort)(info, s)
+			if errors.Is(err, ErrUnhandledStone) {
+				continue
+			}
+			if err != nil {
+				return errors.Join(err, m.Unhook(stone[:i]...))
+			}
+			m.mu.Lock()
+			m.accepted = append(m.accepted, accepted{stone: s, mort: mort})
+			m.mu.Unlock()
+			hooked = true
+			break
+		}
+		if !hooked {
+			return errors.Join(fmt.Errorf("%w %T from %s", ErrUnhandledStone, s, info), m.Unhook(stone[:i]...))
+		}
+	}
+	return nil
+}
+
+// Unhook implements Unhooker, unhooking each Stone from the Mortar that most recently accepted it.
+func (m *firstAccepting) Unhook(stone ...Stone) error {
+	var errs []error
+	for _, s := range stone {
+		m.mu.Lock()
+		var mort Mortar
+		for i := len(m.accepted) - 1; i >= 0; i-- {
+			if a := m.accepted[i]; sameStone(a.stone, s) {
+				mort = a.mort
+				m.accepted = append(m.accepted[:i:i], m.accepted[i+1:]...)
+				break
+			}
+		}
+		m.mu.Unlock()
+		if mort != nil {
+			errs = append(errs, unhookFunc(mort, s))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// mortars implements composite.
+func (m *firstAccepting) mortars() []Mortar {
+	return m.morts
+}
+
+// sameStone compares Stone, treating Stone that panic on comparison, such as those holding slices, as different.
+func sameStone(a, b Stone) (same bool) {
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+	return a == b
+}
@@ -0,0 +1,265 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+package mason
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrDuplicateProvider error = errors.New("duplicate provider")
+	ErrNotProvided       error = errors.New("type not provided")
+	ErrNoContainer       error = errors.New("no container mortar")
+	ErrInvalidBinding    error = errors.New("invalid binding")
+)
+
+var (
+	// interface guard.
+	_ Mortar       = (*Container)(nil)
+	_ ModuleHooker = (*Container)(nil)
+	_ Unhooker     = (*Container)(nil)
+)
+
+type (
+	// Binding is a Stone that provides a value of a type, optionally by name, to a Container.
+	Binding struct {
+		// Type is the provided type, which may be an interface.
+		Type reflect.Type
+		// Name distinguishes multiple providers of the same type.
+		Name string
+		// Value is the provided value.
+		Value any
+		// Module is the Module that provided the value.
+		Module Info
+	}
+	// Provider is an optional Module interface for declaring the types it provides to a Container, so that resolving
+	// a type loads the Module that provides it.
+	Provider interface {
+		// Provides lists the provided types.
+		Provides() []reflect.Type
+	}
+	// Container is a Mortar of typed values provided by Module(s), such as services, keyed by type and name.
+	Container struct {
+		mu       sync.RWMutex
+		bindings map[bindingKey]*Binding
+	}
+	// bindingKey identifies a Binding.
+	bindingKey struct {
+		t    reflect.Type
+		name string
+	}
+)
+
+// NewContainer creates an empty Container.
+func NewContainer() *Container {
+	return &Container{bindings: make(map[bindingKey]*Binding)}
+}
+
+// TypeOf returns the reflect.Type of T, including interface types.
+func TypeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// String implements fmt.Stringer.
+func (b *Binding) String() string {
+	if b.Name == "" {
+		return b.Type.String()
+	}
+	return fmt.Sprintf("%s '%s'", b.Type, b.Name)
+}
+
+// validate checks that a Binding has a Type, and that its Value is assignable to it. A nil Value is only valid for
+// types that may be nil.
+func (b *Binding) validate() error {
+	if b.Type == nil {
+		return fmt.Errorf("%w without type", ErrInvalidBinding)
+	}
+	if b.Value == nil {
+		switch b.Type.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice,
+			reflect.UnsafePointer:
+			return nil
+		}
+		return fmt.Errorf("%w %s, nil value", ErrInvalidBinding, b)
+	}
+	if t := reflect.TypeOf(b.Value); !t.AssignableTo(b.Type) {
+		return fmt.Errorf("%w %s, %s value", ErrInvalidBinding, b, t)
+	}
+	return nil
+}
+
+// Hook implements Mortar for Binding(s) that were not provided by a Module.
+func (c *Container) Hook(stone ...Stone) error {
+	return c.HookModule(Info{}, stone...)
+}
+
+// HookModule implements ModuleHooker. Every Stone must be a valid *Binding, and a type may only be provided once
+// per name.
+func (c *Container) HookModule(info Info, stone ...Stone) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, s := range stone {
+		b, ok := s.(*Binding)
+		if !ok {
+			err := fmt.Errorf("%w %T from %s, container only accepts *mason.Binding", ErrUnhandledStone, s, info)
+			c.unhook(stone[:i]...)
+			return err
+		}
+		if err := b.validate(); err != nil {
+			c.unhook(stone[:i]...)
+			return fmt.Errorf("%w from %s", err, info)
+		}
+		key := bindingKey{t: b.Type, name: b.Name}
+		if other, ok := c.bindings[key]; ok {
+			err := fmt.Errorf("%w of %s by %s, already provided by %s", ErrDuplicateProvider, b, info, other.Module)
+			c.unhook(stone[:i]...)
+			return err
+		}
+		b.Module = info
+		c.bindings[key] = b
+	}
+	return nil
+}
+
+// Unhook implements Unhooker.
+func (c *Container) Unhook(stone ...Stone) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unhook(stone...)
+	return nil
+}
+
+// unhook removes Binding(s). The caller must hold mu.
+func (c *Container) unhook(stone ...Stone) {
+	for _, s := range stone {
+		if b, ok := s.(*Binding); ok {
+			key := bindingKey{t: b.Type, name: b.Name}
+			if c.bindings[key] == b {
+				delete(c.bindings, key)
+			}
+		}
+	}
+}
+
+// Bindings lists the provided Binding(s), ordered by type and name.
+func (c *Container) Bindings() []Binding {
+	c.mu.RLock()
+	bindings := make([]Binding, 0, len(c.bindings))
+	for _, b := range c.bindings {
+		bindings = append(bindings, *b)
+	}
+	c.mu.RUnlock()
+	sort.Slice(bindings, func(i, j int) bool {
+		if a, b := bindings[i].Type.String(), bindings[j].Type.String(); a != b {
+			return a < b
+		}
+		return bindings[i].Name < bindings[j].Name
+	})
+	return bindings
+}
+
+// get returns a Binding by type and name.
+func (c *Container) get(t reflect.Type, name string) (*Binding, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	b, ok := c.bindings[bindingKey{t: t, name: name}]
+	return b, ok
+}
+
+// Provide hooks a value of type T, optionally by name, into the Container of Scaffold(ing) on behalf of the Module
+// being provisioned.
+func Provide[T any](c *Context, v T, name ...string) error {
+	b := &Binding{Type: TypeOf[T](), Value: v}
+	if len(name) > 0 {
+		b.Name = name[0]
+	}
+	return c.Hook(b)
+}
+
+// Resolve returns a value of type T, optionally by name, from the Container of Scaffold(ing). If no value has been
+// provided yet, the first registered Module implementing Provider for T is loaded as a dependency.
+func Resolve[T any](c *Context, name ...string) (v T, err error) {
+	container, ok := containerOf(c)
+	if !ok {
+		return v, ErrNoContainer
+	}
+	t, n := TypeOf[T](), ""
+	if len(name) > 0 {
+		n = name[0]
+	}
+	b, ok := container.get(t, n)
+	if !ok {
+		for _, info := range c.scaffold.providers(t) {
+			if err = c.Load(info); err != nil {
+				return
+			}
+			if b, ok = container.get(t, n); ok {
+				break
+			}
+		}
+	}
+	if !ok {
+		b = &Binding{Type: t, Name: n}
+		return v, fmt.Errorf("%w %s", ErrNotProvided, b)
+	}
+	v, _ = b.Value.(T)
+	return v, nil
+}
+
+// containerOf finds the Container of a Mortar, following Context(s) of nested Scaffold(ing) and composed Mortar(s).
+func containerOf(mort Mortar) (*Container, bool) {
+	switch m := mort.(type) {
+	case *Container:
+		return m, true
+	case *Context:
+		return containerOf(m.scaffold.mort)
+	case composite:
+		for _, child := range m.mortars() {
+			if c, ok := containerOf(child); ok {
+				return c, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// providers lists registered Module(s) that implement Provider for a type, ordered by Info.
+func (s *Scaffold) providers(t reflect.Type) (info []Info) {
+	s.modulesMu.RLock()
+	for _, w := range s.modules {
+		if provider, ok := w.Module.(Provider); ok {
+			for _, provided := range provider.Provides() {
+				if provided == t {
+					info = append(info, w.Info())
+					break
+				}
+			}
+		}
+	}
+	s.modulesMu.RUnlock()
+	sortInfo(info)
+	return
+}
@@ -23,16 +23,41 @@ package mason
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/pedregon/mason/v2/internal/stack"
+	"runtime/debug"
 	"time"
 )
 
 var (
 	// interface guard.
-	_ Mortar = (*Context)(nil)
+	_ Mortar       = (*Context)(nil)
+	_ ModuleHooker = (*Context)(nil)
 )
 
 type (
+	// LoadError is a Context.Load failure. It satisfies errors.Is with the underlying cause, such as
+	// ErrCircularDependency, and is propagated unchanged through dependent Module(s).
+	LoadError struct {
+		// Info is the Module that failed to load.
+		Info Info
+		// Dependency is the offending dependency of Info, if any.
+		Dependency Info
+		// Chain is the dependency chain leading up to the failure, such as foo-1.0.0 -> bar-1.0.0 -> foo-1.0.0.
+		Chain []Info
+		// Err is the underlying cause.
+		Err error
+	}
+	// PanicError is a recovered Module.Provision panic.
+	PanicError struct {
+		// Info is the Module that panicked.
+		Info Info
+		// Value is the recovered value.
+		Value any
+		// Stack is the stack trace of the panicking goroutine.
+		Stack []byte
+	}
 	// Context is a context for loading Module(s) registered in a Scaffold.
 	Context struct {
 		context.Context
@@ -41,6 +66,36 @@ type (
 	}
 )
 
+// Error implements error.
+func (e *LoadError) Error() string {
+	msg := e.Info.String() + " failed to load"
+	if e.Dependency != (Info{}) {
+		msg += " dependency " + e.Dependency.String()
+	}
+	if len(e.Chain) > 0 {
+		msg += " (" + chain(e.Chain) + ")"
+	}
+	return msg + ", " + e.Err.Error()
+}
+
+// Unwrap returns the underlying cause.
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// Error implements error.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%s panicked, %v", e.Info, e.Value)
+}
+
+// Unwrap returns the recovered value if it is an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
 // newContext creates a new Context for a Scaffold.
 func newContext(ctx context.Context, scaffold *Scaffold) *Context {
 	c := new(Context)
@@ -55,57 +110,248 @@ func (c *Context) Hook(stone ...Stone) error {
 	if err := c.Err(); err != nil {
 		return err
 	}
-	return c.scaffold.hook(stone...)
+	current, ok := c.stack.Peek()
+	if err := c.scaffold.hook(current, stone...); err != nil {
+		return err
+	}
+	if ok {
+		c.scaffold.record(current, stone...)
+	}
+	return nil
 }
 
-// Load loads Module dependencies by Info.
-func (c *Context) Load(info ...Info) (err error) {
+// HookModule hooks Stone provided by a Module to mount points for Mortar, such as when Context is the Mortar of
+// nested Scaffold(ing). Like Hook, Stone are recorded against the Module being provisioned, so that they are
+// unhooked along with it.
+func (c *Context) HookModule(info Info, stone ...Stone) error {
+	if err := c.Err(); err != nil {
+		return err
+	}
+	if err := c.scaffold.hook(info, stone...); err != nil {
+		return err
+	}
+	if current, ok := c.stack.Peek(); ok {
+		c.scaffold.record(current, stone...)
+	}
+	return nil
+}
+
+// Require loads the highest registered version of a Module dependency satisfying a semantic version constraint,
+// such as ">=1.2, <2". A ConstraintError is returned if no registered version matches.
+func (c *Context) Require(name, constraint string) (info Info, err error) {
 	if err = c.Err(); err != nil {
 		return
 	}
+	if info, err = c.scaffold.resolve(name, constraint); err != nil {
+		e := c.event(Info{Name: name}, PhaseFailed)
+		e.err = err
+		c.scaffold.publish(e)
+		return
+	}
+	err = c.Load(info)
+	return
+}
+
+// Load loads Module dependencies by Info.
+func (c *Context) Load(info ...Info) (err error) {
 	for _, i := range info {
-		mod, exist, loaded := c.scaffold.get(i)
-		if !exist {
-			err = ErrInvalidModule
+		if err = c.load(i, false); err != nil {
 			if c.stack.Size() > 0 {
-				err = ErrMissingDependency
+				// fail the dependent even if Provision swallows the error
+				c.stack.Log(err)
 			}
-			c.scaffold.publish(Event{Info: i, err: err})
 			return
 		}
-		if !loaded {
-			if current, ok := c.stack.Peek(); ok && current.String() == i.String() {
-				err = ErrSelfReferentialDependency
-				c.scaffold.publish(Event{Info: i, err: err})
-				return
+	}
+	return
+}
+
+// LoadOptional loads Module dependencies by Info that are registered, skipping those that are not without error.
+// Circular dependencies and failures of registered Module(s) still fail. The loaded dependencies are returned.
+func (c *Context) LoadOptional(info ...Info) (loaded []Info, err error) {
+	for _, i := range info {
+		if _, exist := c.scaffold.get(i); !exist {
+			continue
+		}
+		if err = c.load(i, true); err != nil {
+			if c.stack.Size() > 0 {
+				c.stack.Log(err)
 			}
-			if c.stack.Has(i) {
-				err = ErrCircularDependency
-				c.scaffold.publish(Event{Info: i, err: err})
-				return
+			return
+		}
+		loaded = append(loaded, i)
+	}
+	return
+}
+
+// load loads a Module dependency by Info, sharing the result with concurrent loads of the same Module.
+func (c *Context) load(i Info, optional bool) (err error) {
+	if err = c.Err(); err != nil {
+		return
+	}
+	mod, exist := c.scaffold.get(i)
+	if !exist {
+		if c.stack.Size() > 0 {
+			return c.fail(i, nil, ErrMissingDependency)
+		}
+		return c.fail(i, nil, ErrInvalidModule)
+	}
+	if current, ok := c.stack.Peek(); ok {
+		if current == i {
+			return c.fail(i, nil, ErrSelfReferentialDependency)
+		}
+		if c.stack.Has(i) {
+			return c.fail(i, nil, ErrCircularDependency)
+		}
+		c.scaffold.depend(current, optional, i)
+	}
+	if err = c.scaffold.failure(mod); err != nil {
+		return
+	}
+	f, owned, cycle := c.scaffold.acquire(mod, c.stack)
+	if cycle != nil {
+		return c.fail(i, cycle, ErrCircularDependency)
+	}
+	if f == nil {
+		// already loaded
+		return
+	}
+	if !owned {
+		return c.scaffold.wait(c, f)
+	}
+	if err = c.scaffold.provisioning(mod); err != nil {
+		c.scaffold.release(i, f, err)
+		return
+	}
+	start := time.Now()
+	attempt, err := c.provision(mod)
+	e := c.event(i, PhaseProvisioned)
+	if err != nil {
+		c.scaffold.fail(mod, err)
+		e.Phase = PhaseFailed
+	}
+	c.scaffold.release(i, f, err)
+	e.Time, e.Duration, e.Attempt, e.err = start, time.Since(start), attempt, err
+	c.scaffold.publish(e)
+	return
+}
+
+// fail publishes and returns a LoadError for a dependency of the current Module. The chain defaults to the
+// dependency stack.
+func (c *Context) fail(dep Info, chain []Info, err error) error {
+	e := &LoadError{Info: dep, Chain: chain, Err: err}
+	if e.Chain == nil {
+		e.Chain = append(c.stack.Values(), dep)
+	}
+	if current, ok := c.stack.Peek(); ok {
+		e.Info, e.Dependency = current, dep
+	}
+	event := c.event(dep, PhaseFailed)
+	event.err = e
+	c.scaffold.publish(event)
+	return e
+}
+
+// event creates an Event for a Module annotated with the dependency chain.
+func (c *Context) event(info Info, phase Phase) Event {
+	e := Event{Info: info, Phase: phase, Chain: c.stack.Values()}
+	if n := len(e.Chain); n == 0 || e.Chain[n-1] != info {
+		e.Chain = append(e.Chain, info)
+	}
+	if n := len(e.Chain); n > 1 {
+		e.Parent = e.Chain[n-2]
+	}
+	return e
+}
+
+// provision provisions a Module on top of the dependency stack, retrying according to its RetryPolicy. The number
+// of attempts is returned.
+func (c *Context) provision(mod *moduleWrapper) (attempt int, err error) {
+	c.stack.Push(mod.Info())
+	defer func() {
+		if err != nil && c.scaffold.transactional {
+			if _err := c.scaffold.rollback(mod); _err != nil {
+				err = errors.Join(err, _err)
 			}
-			c.stack.Push(i)
-			index := c.stack.Size() - 1
-			start := time.Now()
-			if err = mod.Provision(c); err != nil {
-				c.stack.Log(err)
+		}
+		var e *LoadError
+		if err != nil && !errors.As(err, &e) {
+			err = &LoadError{Info: mod.Info(), Chain: c.stack.Values(), Err: err}
+		}
+		c.stack.Pop()
+	}()
+	start := time.Now()
+	policy := c.scaffold.retryPolicy(mod.Info())
+	for attempt = 1; ; attempt++ {
+		e := c.event(mod.Info(), PhaseProvisioning)
+		e.Time, e.Attempt = time.Now(), attempt
+		c.scaffold.publish(e)
+		if err = c.attempt(mod); err == nil || attempt >= policy.MaxAttempts || !policy.retryable(mod.Info(), err) {
+			break
+		}
+		e.Phase, e.Duration, e.err = PhaseFailed, time.Since(e.Time), err
+		c.scaffold.publish(e)
+		if c.scaffold.transactional {
+			if err = c.scaffold.rollback(mod); err != nil {
 				return
 			}
-			c.scaffold.set(i, start)
-			for {
-				if err = c.stack.Err(); err != nil {
-					c.scaffold.publish(Event{Info: i, err: err})
-					return
-				}
-				if c.stack.Size()-1 == index {
-					c.scaffold.publish(Event{Info: i, err: err})
-					break
-				}
-				if top, ok := c.stack.Pop(); ok {
-					c.scaffold.depend(mod, top)
-				}
-			}
 		}
+		// forget dependency failures from the previous attempt
+		c.stack.Log(nil)
+		select {
+		case <-time.After(policy.backoff(attempt)):
+		case <-c.Done():
+			err = c.Err()
+			return
+		}
+	}
+	if err != nil {
+		return
 	}
+	err = c.scaffold.set(mod.Info(), start)
 	return
 }
+
+// attempt provisions a Module once, within its deadline budget.
+func (c *Context) attempt(mod *moduleWrapper) error {
+	timeout := c.scaffold.timeout(mod.Module)
+	if timeout <= 0 {
+		return c.provide(mod)
+	}
+	ctx, cancel := context.WithTimeout(c.Context, timeout)
+	defer cancel()
+	p := c.with(ctx)
+	err := p.provide(mod)
+	if errors.Is(p.Err(), context.DeadlineExceeded) && c.Err() == nil {
+		err = fmt.Errorf("%w after %s", ErrProvisionTimeout, timeout)
+	}
+	return err
+}
+
+// provide loads static dependencies and calls Module.Provision.
+func (c *Context) provide(mod *moduleWrapper) (err error) {
+	if dependent, ok := mod.Module.(Dependent); ok {
+		if err = c.Load(dependent.Dependencies()...); err != nil {
+			return
+		}
+	}
+	if err = c.call(mod); err != nil {
+		return
+	}
+	return c.stack.Err()
+}
+
+// with derives a child Context sharing the dependency stack.
+func (c *Context) with(ctx context.Context) *Context {
+	return &Context{Context: ctx, scaffold: c.scaffold, stack: c.stack}
+}
+
+// call calls Module.Provision, recovering any panic as a PanicError so that bookkeeping stays consistent.
+func (c *Context) call(mod Module) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Info: mod.Info(), Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return mod.Provision(c)
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+package mason
+
+import "time"
+
+// Phase(s) of the Module lifecycle.
+const (
+	PhaseRegistered   Phase = "registered"
+	PhaseSkipped      Phase = "skipped"
+	PhaseProvisioning Phase = "provisioning"
+	PhaseProvisioned  Phase = "provisioned"
+	PhaseFailed       Phase = "failed"
+	PhaseStarted      Phase = "started"
+	PhaseStopped      Phase = "stopped"
+	PhaseUnloaded     Phase = "unloaded"
+)
+
+type (
+	// Phase is a Module lifecycle phase.
+	Phase string
+	// Event is a Module lifecycle event.
+	Event struct {
+		Info
+		// Phase is the lifecycle phase of Info.
+		Phase Phase
+		// Time is when the Event occurred. For an Event with a Duration, it is when the phase started, so that the
+		// phase ended at Time plus Duration.
+		Time time.Time
+		// Duration is how long the phase took, such as the provisioning runtime.
+		Duration time.Duration
+		// Parent is the Module that loaded Info as a dependency, if any.
+		Parent Info
+		// Chain is the dependency chain leading up to Info while loading.
+		Chain []Info
+		// Attempt is the provisioning attempt number, starting at 1.
+		Attempt int
+		err     error
+	}
+)
+
+// Err returns nil if a phase succeeded or an error if a problem occurred.
+func (e Event) Err() error {
+	return e.err
+}
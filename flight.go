@@ -0,0 +1,94 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+package mason
+
+import (
+	"github.com/pedregon/mason/v2/internal/stack"
+)
+
+type (
+	// flight is an in-progress Module provision shared by concurrent loads, similar to
+	// https://pkg.go.dev/golang.org/x/sync/singleflight.
+	flight struct {
+		info  Info
+		done  chan struct{}
+		err   error
+		owner *stack.Stack[Info]
+	}
+)
+
+// acquire claims a Module for provisioning by the owner dependency stack. If another stack is already provisioning
+// the Module, its flight is returned to wait on instead. A nil flight means the Module is already loaded. Waiting
+// on a stack that transitively waits on the owner would deadlock, so the circular dependency chain is returned.
+func (s *Scaffold) acquire(mod *moduleWrapper, owner *stack.Stack[Info]) (f *flight, owned bool, cycle []Info) {
+	info := mod.Info()
+	s.flightsMu.Lock()
+	defer s.flightsMu.Unlock()
+	if f, ok := s.flights[info.String()]; ok {
+		cycle = append(owner.Values(), info)
+		for next, awaited := f, info; next.owner != owner; awaited = next.info {
+			values := next.owner.Values()
+			for i := range values {
+				if values[i] == awaited {
+					cycle = append(cycle, values[i+1:]...)
+					break
+				}
+			}
+			if next, ok = s.flights[s.waiting[next.owner]]; !ok {
+				s.waiting[owner] = info.String()
+				return f, false, nil
+			}
+			cycle = append(cycle, next.info)
+		}
+		return nil, false, cycle
+	}
+	if s.isLoaded(mod) {
+		return nil, false, nil
+	}
+	f = &flight{info: info, done: make(chan struct{}), owner: owner}
+	s.flights[info.String()] = f
+	return f, true, nil
+}
+
+// release completes a flight, waking any waiters.
+func (s *Scaffold) release(info Info, f *flight, err error) {
+	s.flightsMu.Lock()
+	defer s.flightsMu.Unlock()
+	f.err = err
+	delete(s.flights, info.String())
+	close(f.done)
+}
+
+// wait waits on a flight claimed by another dependency stack.
+func (s *Scaffold) wait(c *Context, f *flight) (err error) {
+	defer func() {
+		s.flightsMu.Lock()
+		delete(s.waiting, c.stack)
+		s.flightsMu.Unlock()
+	}()
+	select {
+	case <-f.done:
+		return f.err
+	case <-c.Done():
+		return c.Err()
+	}
+}
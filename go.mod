@@ -1,6 +1,6 @@
 module github.com/pedregon/mason/v2
 
-go 1.19
+go 1.21
 
 retract v2.0.0 // Breaking code
 retract v2.0.1 // Breaking bug
@@ -0,0 +1,185 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+// Package graph renders a mason.Snapshot dependency graph as Graphviz DOT, Mermaid flowchart, or JSON. Output is
+// deterministic so that it may be checked in and diffed.
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pedregon/mason/v2"
+	"io"
+	"strings"
+	"time"
+)
+
+// Node statuses.
+const (
+	StatusLoaded     = "loaded"
+	StatusSkipped    = "skipped"
+	StatusFailed     = "failed"
+	StatusRegistered = "registered"
+)
+
+type (
+	// Option is a functional option for rendering.
+	Option func(*options)
+	// options are rendering options.
+	options struct {
+		runtime bool
+	}
+	// Graph is the stable JSON schema of a dependency graph.
+	Graph struct {
+		Nodes []Node `json:"nodes"`
+		Edges []Edge `json:"edges"`
+	}
+	// Node is the stable JSON schema of a Module.
+	Node struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Status  string `json:"status"`
+		Runtime int64  `json:"runtime_ns,omitempty"`
+	}
+	// Edge is the stable JSON schema of a Module dependency, where From depends on To.
+	Edge struct {
+		From     string `json:"from"`
+		To       string `json:"to"`
+		Optional bool   `json:"optional,omitempty"`
+	}
+)
+
+// OmitRuntime omits Module runtimes, which vary between runs, for reproducible output.
+func OmitRuntime() Option {
+	return func(o *options) {
+		o.runtime = false
+	}
+}
+
+// New converts a mason.Snapshot to the stable JSON schema.
+func New(snap *mason.Snapshot, opt ...Option) *Graph {
+	o := &options{runtime: true}
+	for _, fn := range opt {
+		fn(o)
+	}
+	g := &Graph{Nodes: make([]Node, 0, len(snap.Nodes)), Edges: make([]Edge, 0, len(snap.Edges))}
+	for _, n := range snap.Nodes {
+		node := Node{ID: n.Info.String(), Name: n.Info.Name, Version: n.Info.Version, Status: status(n)}
+		if o.runtime {
+			node.Runtime = n.Runtime.Nanoseconds()
+		}
+		g.Nodes = append(g.Nodes, node)
+	}
+	for _, e := range snap.Edges {
+		g.Edges = append(g.Edges, Edge{From: e.From.String(), To: e.To.String(), Optional: e.Optional})
+	}
+	return g
+}
+
+// status describes the load status of a Node.
+func status(n mason.Node) string {
+	switch {
+	case n.Skipped:
+		return StatusSkipped
+	case n.Loaded:
+		return StatusLoaded
+	case n.State == mason.StateFailed:
+		return StatusFailed
+	}
+	return StatusRegistered
+}
+
+// label is a human-readable Node description.
+func (n Node) label() []string {
+	lines := []string{n.Name + " " + n.Version, n.Status}
+	if n.Runtime > 0 {
+		lines[1] += " " + time.Duration(n.Runtime).String()
+	}
+	return lines
+}
+
+// JSON renders the dependency graph as indented JSON.
+func JSON(w io.Writer, snap *mason.Snapshot, opt ...Option) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(New(snap, opt...))
+}
+
+// DOT renders the dependency graph as Graphviz DOT. Edges point from a Module to its dependency, and optional
+// dependencies are dashed.
+func DOT(w io.Writer, snap *mason.Snapshot, opt ...Option) error {
+	g := New(snap, opt...)
+	var b strings.Builder
+	b.WriteString("digraph mason {\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "\t%s [label=%s", quote(n.ID), quote(strings.Join(n.label(), "\n")))
+		switch n.Status {
+		case StatusSkipped:
+			b.WriteString(", style=dashed")
+		case StatusFailed:
+			b.WriteString(", color=red")
+		}
+		b.WriteString("];\n")
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "\t%s -> %s", quote(e.From), quote(e.To))
+		if e.Optional {
+			b.WriteString(" [style=dashed]")
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// quote quotes a DOT identifier.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// Mermaid renders the dependency graph as a Mermaid flowchart. Edges point from a Module to its dependency, and
+// optional dependencies are dotted.
+func Mermaid(w io.Writer, snap *mason.Snapshot, opt ...Option) error {
+	g := New(snap, opt...)
+	ids := make(map[string]string, len(g.Nodes))
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+		label := strings.NewReplacer(`"`, "#quot;").Replace(strings.Join(n.label(), "<br/>"))
+		fmt.Fprintf(&b, "\t%s[\"%s\"]:::%s\n", ids[n.ID], label, n.Status)
+	}
+	for _, e := range g.Edges {
+		arrow := "-->"
+		if e.Optional {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "\t%s %s %s\n", ids[e.From], arrow, ids[e.To])
+	}
+	b.WriteString("\tclassDef " + StatusLoaded + " stroke-width: 2px\n")
+	b.WriteString("\tclassDef " + StatusRegistered + " stroke-width: 1px\n")
+	b.WriteString("\tclassDef " + StatusSkipped + " stroke-dasharray: 5 5\n")
+	b.WriteString("\tclassDef " + StatusFailed + " stroke: red\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+package graph_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/pedregon/mason/v2"
+	"github.com/pedregon/mason/v2/graph"
+	"testing"
+)
+
+var (
+	_ mason.Mortar = (*nopMortar)(nil)
+	_ mason.Module = (*module)(nil)
+)
+
+type (
+	nopMortar struct{}
+	module    struct {
+		name     string
+		version  string
+		deps     []mason.Info
+		optional []mason.Info
+		err      error
+	}
+)
+
+func (nopMortar) Hook(_ ...mason.Stone) error {
+	return nil
+}
+
+func (mod module) Info() mason.Info {
+	return mason.Info{Name: mod.name, Version: mod.version}
+}
+
+func (mod module) Provision(c *mason.Context) error {
+	if _, err := c.LoadOptional(mod.optional...); err != nil {
+		return err
+	}
+	if err := c.Load(mod.deps...); err != nil {
+		return err
+	}
+	return mod.err
+}
+
+func snapshot(t *testing.T) *mason.Snapshot {
+	baz := module{name: "baz", version: "1.0.0"}
+	bar := module{name: "bar", version: "1.0.0", deps: []mason.Info{baz.Info()}}
+	qux := module{name: "qux", version: "1.0.0"}
+	foo := module{name: "foo", version: "1.0.0", deps: []mason.Info{bar.Info()}, optional: []mason.Info{baz.Info(), qux.Info()}}
+	scaffold := mason.New(nopMortar{}, mason.SkipOption(func(info mason.Info) bool {
+		return info == qux.Info()
+	}))
+	if err := scaffold.Load(context.TODO(), foo, qux, bar, baz); err != nil {
+		t.Fatal(err)
+	}
+	return scaffold.Snapshot()
+}
+
+func TestDOT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := graph.DOT(&buf, snapshot(t), graph.OmitRuntime()); err != nil {
+		t.Fatal(err)
+	}
+	expected := `digraph mason {
+	"bar-1.0.0" [label="bar 1.0.0\nloaded"];
+	"baz-1.0.0" [label="baz 1.0.0\nloaded"];
+	"foo-1.0.0" [label="foo 1.0.0\nloaded"];
+	"qux-1.0.0" [label="qux 1.0.0\nskipped", style=dashed];
+	"bar-1.0.0" -> "baz-1.0.0";
+	"foo-1.0.0" -> "bar-1.0.0";
+	"foo-1.0.0" -> "baz-1.0.0" [style=dashed];
+}
+`
+	if buf.String() != expected {
+		t.Fatal(buf.String())
+	}
+}
+
+func TestMermaid(t *testing.T) {
+	var buf bytes.Buffer
+	if err := graph.Mermaid(&buf, snapshot(t), graph.OmitRuntime()); err != nil {
+		t.Fatal(err)
+	}
+	expected := `flowchart TD
+	n0["bar 1.0.0<br/>loaded"]:::loaded
+	n1["baz 1.0.0<br/>loaded"]:::loaded
+	n2["foo 1.0.0<br/>loaded"]:::loaded
+	n3["qux 1.0.0<br/>skipped"]:::skipped
+	n0 --> n1
+	n2 --> n0
+	n2 -.-> n1
+	classDef loaded stroke-width: 2px
+	classDef registered stroke-width: 1px
+	classDef skipped stroke-dasharray: 5 5
+	classDef failed stroke: red
+`
+	if buf.String() != expected {
+		t.Fatal(buf.String())
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var buf bytes.Buffer
+	snap := snapshot(t)
+	if err := graph.JSON(&buf, snap, graph.OmitRuntime()); err != nil {
+		t.Fatal(err)
+	}
+	expected := `{
+  "nodes": [
+    {
+      "id": "bar-1.0.0",
+      "name": "bar",
+      "version": "1.0.0",
+      "status": "loaded"
+    },
+    {
+      "id": "baz-1.0.0",
+      "name": "baz",
+      "version": "1.0.0",
+      "status": "loaded"
+    },
+    {
+      "id": "foo-1.0.0",
+      "name": "foo",
+      "version": "1.0.0",
+      "status": "loaded"
+    },
+    {
+      "id": "qux-1.0.0",
+      "name": "qux",
+      "version": "1.0.0",
+      "status": "skipped"
+    }
+  ],
+  "edges": [
+    {
+      "from": "bar-1.0.0",
+      "to": "baz-1.0.0"
+    },
+    {
+      "from": "foo-1.0.0",
+      "to": "bar-1.0.0"
+    },
+    {
+      "from": "foo-1.0.0",
+      "to": "baz-1.0.0",
+      "optional": true
+    }
+  ]
+}
+`
+	if buf.String() != expected {
+		t.Fatal(buf.String())
+	}
+	if g := graph.New(snap); g.Nodes[0].Runtime <= 0 {
+		t.Fatal(g.Nodes[0])
+	}
+}
+
+func TestNew(t *testing.T) {
+	errFailed := errors.New("failed")
+	bar := module{name: "bar", version: "1.0.0", err: errFailed}
+	foo := module{name: "foo", version: "1.0.0", deps: []mason.Info{bar.Info()}}
+	baz := module{name: "baz", version: "1.0.0"}
+	scaffold := mason.New(nopMortar{}, mason.ContinueOnError())
+	if err := scaffold.Load(context.TODO(), foo, bar, baz); !errors.Is(err, errFailed) {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := graph.DOT(&buf, scaffold.Snapshot(), graph.OmitRuntime()); err != nil {
+		t.Fatal(err)
+	}
+	expected := `digraph mason {
+	"bar-1.0.0" [label="bar 1.0.0\nfailed", color=red];
+	"baz-1.0.0" [label="baz 1.0.0\nloaded"];
+	"foo-1.0.0" [label="foo 1.0.0\nfailed", color=red];
+	"foo-1.0.0" -> "bar-1.0.0";
+}
+`
+	if buf.String() != expected {
+		t.Fatal(buf.String())
+	}
+}
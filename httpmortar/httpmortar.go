@@ -0,0 +1,295 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+// Package httpmortar mounts net/http routes hooked by mason.Module(s) on an *http.ServeMux. Routes are dispatched
+// by method per pattern, so that several Module(s) may share a pattern with different methods, and conflicting
+// routes are reported with both Module(s).
+package httpmortar
+
+import (
+	"errors"
+	"fmt"
+	"github.com/pedregon/mason/v2"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrInvalidRoute     error = errors.New("invalid route")
+	ErrConflictingRoute error = errors.New("conflicting route")
+)
+
+var (
+	// interface guard.
+	_ mason.Mortar       = (*Mortar)(nil)
+	_ mason.ModuleHooker = (*Mortar)(nil)
+	_ mason.Unhooker     = (*Mortar)(nil)
+	_ http.Handler       = (*Mortar)(nil)
+)
+
+type (
+	// Middleware wraps an http.Handler.
+	Middleware func(next http.Handler) http.Handler
+	// Route is a Stone that mounts an http.Handler.
+	Route struct {
+		// Method is the HTTP method, or empty for any method.
+		Method string
+		// Pattern is the http.ServeMux pattern, such as /users/, prefixed by the Module prefix, if any.
+		Pattern string
+		// Handler handles matching requests.
+		Handler http.Handler
+		// Middleware wraps Handler, such that the first Middleware is the outermost.
+		Middleware []Middleware
+	}
+	// Option is a functional option for Mortar.
+	Option func(m *Mortar)
+	// Mortar is a mason.Mortar that mounts *Route Stone on an *http.ServeMux.
+	Mortar struct {
+		mux      *http.ServeMux
+		prefixes map[mason.Info]string
+		mu       sync.RWMutex
+		patterns map[string]*endpoint
+	}
+	// endpoint dispatches requests for a pattern by method.
+	endpoint struct {
+		mortar  *Mortar
+		owner   mason.Info
+		methods map[string]*mounted
+	}
+	// mounted is a Route mounted by a Module.
+	mounted struct {
+		route   *Route
+		module  mason.Info
+		handler http.Handler
+	}
+)
+
+// Handle creates a Route.
+func Handle(method, pattern string, handler http.Handler, middleware ...Middleware) *Route {
+	return &Route{Method: method, Pattern: pattern, Handler: handler, Middleware: middleware}
+}
+
+// HandleFunc creates a Route for a handler function.
+func HandleFunc(method, pattern string, handler http.HandlerFunc, middleware ...Middleware) *Route {
+	return Handle(method, pattern, handler, middleware...)
+}
+
+// Prefix mounts the Route(s) of a Module under a path prefix, such as /api/v1.
+func Prefix(info mason.Info, prefix string) Option {
+	return func(m *Mortar) {
+		m.prefixes[info] = strings.TrimSuffix(prefix, "/")
+	}
+}
+
+// New creates a Mortar for an *http.ServeMux. A new *http.ServeMux is created if mux is nil.
+func New(mux *http.ServeMux, opt ...Option) *Mortar {
+	if mux == nil {
+		mux = http.NewServeMux()
+	}
+	m := &Mortar{
+		mux:      mux,
+		prefixes: make(map[mason.Info]string),
+		patterns: make(map[string]*endpoint),
+	}
+	for _, fn := range opt {
+		fn(m)
+	}
+	return m
+}
+
+// ServeHTTP implements http.Handler by delegating to the *http.ServeMux.
+func (m *Mortar) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mux.ServeHTTP(w, r)
+}
+
+// Hook implements mason.Mortar for Route(s) that were not provided by a Module.
+func (m *Mortar) Hook(stone ...mason.Stone) error {
+	return m.HookModule(mason.Info{}, stone...)
+}
+
+// HookModule implements mason.ModuleHooker. Every Stone must be a *Route. A Route conflicts with another mounted on
+// the same pattern if either has the same method or any method.
+func (m *Mortar) HookModule(info mason.Info, stone ...mason.Stone) error {
+	for i, s := range stone {
+		route, ok := s.(*Route)
+		if !ok {
+			_ = m.Unhook(stone[:i]...)
+			return fmt.Errorf("%w %T from %s", mason.ErrUnhandledStone, s, info)
+		}
+		if err := m.mount(info, route); err != nil {
+			_ = m.Unhook(stone[:i]...)
+			return err
+		}
+	}
+	return nil
+}
+
+// mount mounts a Route provided by a Module.
+func (m *Mortar) mount(info mason.Info, route *Route) (err error) {
+	if route.Handler == nil || !strings.HasPrefix(route.Pattern, "/") {
+		return fmt.Errorf("%w %s '%s' from %s", ErrInvalidRoute, method(route.Method), route.Pattern, info)
+	}
+	pattern := m.prefixes[info] + route.Pattern
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.patterns[pattern]
+	if !ok {
+		e = &endpoint{mortar: m, owner: info, methods: make(map[string]*mounted)}
+		if err = register(m.mux, pattern, e); err != nil {
+			if err = register(http.NewServeMux(), pattern, http.NotFoundHandler()); err != nil {
+				return fmt.Errorf("%w %s '%s' from %s, %v", ErrInvalidRoute, method(route.Method), pattern, info, err)
+			}
+			if other, ok := m.overlap(pattern); ok {
+				return fmt.Errorf("%w %s '%s' from %s, overlaps '%s' already mounted by %s", ErrConflictingRoute,
+					method(route.Method), pattern, info, other, m.patterns[other].module())
+			}
+			return fmt.Errorf("%w %s '%s' from %s, %v", ErrInvalidRoute, method(route.Method), pattern, info, err)
+		}
+		m.patterns[pattern] = e
+	}
+	if meth, ok := e.conflict(route.Method); ok {
+		return fmt.Errorf("%w %s '%s' from %s, already mounted by %s as %s", ErrConflictingRoute,
+			method(route.Method), pattern, info, e.methods[meth].module, method(meth))
+	}
+	handler := route.Handler
+	for i := len(route.Middleware) - 1; i >= 0; i-- {
+		handler = route.Middleware[i](handler)
+	}
+	e.methods[route.Method] = &mounted{route: route, module: info, handler: handler}
+	return nil
+}
+
+// Unhook implements mason.Unhooker. Patterns stay registered on the *http.ServeMux, which cannot unregister them,
+// but respond with 404 Not Found once every Route is unhooked.
+func (m *Mortar) Unhook(stone ...mason.Stone) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range stone {
+		route, ok := s.(*Route)
+		if !ok {
+			continue
+		}
+		for _, e := range m.patterns {
+			if mnt, ok := e.methods[route.Method]; ok && mnt.route == route {
+				delete(e.methods, route.Method)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// overlap finds a mounted pattern that conflicts with a valid pattern on an *http.ServeMux, such as /items/{id} and
+// /items/{name} since Go 1.22. The caller must hold mu.
+func (m *Mortar) overlap(pattern string) (string, bool) {
+	patterns := make([]string, 0, len(m.patterns))
+	for other := range m.patterns {
+		patterns = append(patterns, other)
+	}
+	sort.Strings(patterns)
+	for _, other := range patterns {
+		mux := http.NewServeMux()
+		if register(mux, other, http.NotFoundHandler()) == nil && register(mux, pattern, http.NotFoundHandler()) != nil {
+			return other, true
+		}
+	}
+	return "", false
+}
+
+// module returns the Module mounting a pattern, falling back to the Module that first mounted it.
+func (e *endpoint) module() mason.Info {
+	methods := make([]string, 0, len(e.methods))
+	for meth := range e.methods {
+		methods = append(methods, meth)
+	}
+	if len(methods) == 0 {
+		return e.owner
+	}
+	sort.Strings(methods)
+	return e.methods[methods[0]].module
+}
+
+// conflict finds a mounted method that conflicts with another, preferring the same method. The caller must hold mu.
+func (e *endpoint) conflict(meth string) (string, bool) {
+	if _, ok := e.methods[meth]; ok {
+		return meth, true
+	}
+	if _, ok := e.methods[""]; ok {
+		return "", true
+	}
+	if meth != "" || len(e.methods) == 0 {
+		return "", false
+	}
+	methods := make([]string, 0, len(e.methods))
+	for other := range e.methods {
+		methods = append(methods, other)
+	}
+	sort.Strings(methods)
+	return methods[0], true
+}
+
+// ServeHTTP implements http.Handler by dispatching on the request method.
+func (e *endpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mortar.mu.RLock()
+	mnt, ok := e.methods[r.Method]
+	if !ok && r.Method == http.MethodHead {
+		mnt, ok = e.methods[http.MethodGet]
+	}
+	if !ok {
+		mnt, ok = e.methods[""]
+	}
+	allow := make([]string, 0, len(e.methods))
+	for meth := range e.methods {
+		allow = append(allow, meth)
+	}
+	e.mortar.mu.RUnlock()
+	switch {
+	case ok:
+		mnt.handler.ServeHTTP(w, r)
+	case len(allow) == 0:
+		http.NotFound(w, r)
+	default:
+		sort.Strings(allow)
+		w.Header().Set("Allow", strings.Join(allow, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// register registers a pattern on an *http.ServeMux, recovering its panics as errors.
+func register(mux *http.ServeMux, pattern string, handler http.Handler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	mux.Handle(pattern, handler)
+	return
+}
+
+// method names an HTTP method for errors.
+func method(m string) string {
+	if m == "" {
+		return "ANY"
+	}
+	return m
+}
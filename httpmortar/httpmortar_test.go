@@ -0,0 +1,158 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+package httpmortar_test
+
+import (
+	"context"
+	"errors"
+	"github.com/pedregon/mason/v2"
+	"github.com/pedregon/mason/v2/httpmortar"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var (
+	_ mason.Module = (*module)(nil)
+)
+
+type (
+	module struct {
+		name   string
+		routes []mason.Stone
+	}
+)
+
+func (mod module) Info() mason.Info {
+	return mason.Info{Name: mod.name, Version: "1.0.0"}
+}
+
+func (mod module) Provision(c *mason.Context) error {
+	return c.Hook(mod.routes...)
+}
+
+func text(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, body)
+	}
+}
+
+func header(key, value string) httpmortar.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(key, value)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func get(t *testing.T, srv *httptest.Server, method, path string) (int, string, http.Header) {
+	req, err := http.NewRequest(method, srv.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body), resp.Header
+}
+
+func TestMortar(t *testing.T) {
+	users := module{name: "users", routes: []mason.Stone{
+		httpmortar.HandleFunc(http.MethodGet, "/users", text("list"), header("X-Module", "users")),
+		httpmortar.HandleFunc(http.MethodPost, "/users", text("create")),
+	}}
+	health := module{name: "health", routes: []mason.Stone{
+		httpmortar.HandleFunc("", "/healthz", text("ok")),
+	}}
+	mort := httpmortar.New(nil, httpmortar.Prefix(users.Info(), "/api/v1/"))
+	scaffold := mason.New(mort)
+	if err := scaffold.Load(context.TODO(), users, health); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(mort)
+	defer srv.Close()
+	tests := []struct {
+		method, path string
+		status       int
+		body         string
+	}{
+		{http.MethodGet, "/api/v1/users", http.StatusOK, "list"},
+		{http.MethodPost, "/api/v1/users", http.StatusOK, "create"},
+		{http.MethodDelete, "/api/v1/users", http.StatusMethodNotAllowed, "Method Not Allowed\n"},
+		{http.MethodPut, "/healthz", http.StatusOK, "ok"},
+		{http.MethodGet, "/users", http.StatusNotFound, "404 page not found\n"},
+	}
+	for _, tt := range tests {
+		status, body, _ := get(t, srv, tt.method, tt.path)
+		if status != tt.status || body != tt.body {
+			t.Errorf("%s %s = %d %q", tt.method, tt.path, status, body)
+		}
+	}
+	if _, _, h := get(t, srv, http.MethodGet, "/api/v1/users"); h.Get("X-Module") != "users" {
+		t.Error(h)
+	}
+	if _, _, h := get(t, srv, http.MethodDelete, "/api/v1/users"); h.Get("Allow") != "GET, POST" {
+		t.Error(h)
+	}
+	// unload
+	if err := scaffold.Unload(context.TODO(), users.Info(), false); err != nil {
+		t.Fatal(err)
+	}
+	if status, _, _ := get(t, srv, http.MethodGet, "/api/v1/users"); status != http.StatusNotFound {
+		t.Error(status)
+	}
+}
+
+func TestMortar_Conflict(t *testing.T) {
+	foo := module{name: "foo", routes: []mason.Stone{httpmortar.HandleFunc(http.MethodGet, "/items", text("foo"))}}
+	bar := module{name: "bar", routes: []mason.Stone{httpmortar.HandleFunc("", "/items", text("bar"))}}
+	mort := httpmortar.New(http.NewServeMux())
+	scaffold := mason.New(mort)
+	if err := scaffold.Load(context.TODO(), foo); err != nil {
+		t.Fatal(err)
+	}
+	err := scaffold.Load(context.TODO(), bar)
+	if !errors.Is(err, httpmortar.ErrConflictingRoute) {
+		t.Fatal(err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "ANY '/items' from bar-1.0.0, already mounted by foo-1.0.0 as GET") {
+		t.Fatal(msg)
+	}
+	// invalid
+	baz := module{name: "baz", routes: []mason.Stone{httpmortar.HandleFunc(http.MethodGet, "items", text("baz"))}}
+	if err = scaffold.Load(context.TODO(), baz); !errors.Is(err, httpmortar.ErrInvalidRoute) {
+		t.Fatal(err)
+	}
+	qux := module{name: "qux", routes: []mason.Stone{"qux"}}
+	if err = scaffold.Load(context.TODO(), qux); !errors.Is(err, mason.ErrUnhandledStone) {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+//go:build go1.22
+
+//go:debug httpmuxgo121=0
+
+package httpmortar_test
+
+import (
+	"context"
+	"errors"
+	"github.com/pedregon/mason/v2"
+	"github.com/pedregon/mason/v2/httpmortar"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMortar_Overlap(t *testing.T) {
+	foo := module{name: "foo", routes: []mason.Stone{httpmortar.HandleFunc(http.MethodGet, "/items/{id}", text("foo"))}}
+	bar := module{name: "bar", routes: []mason.Stone{httpmortar.HandleFunc(http.MethodGet, "/items/{name}", text("bar"))}}
+	scaffold := mason.New(httpmortar.New(nil))
+	if err := scaffold.Load(context.TODO(), foo); err != nil {
+		t.Fatal(err)
+	}
+	err := scaffold.Load(context.TODO(), bar)
+	if !errors.Is(err, httpmortar.ErrConflictingRoute) {
+		t.Fatal(err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "GET '/items/{name}' from bar-1.0.0, overlaps '/items/{id}' already mounted by foo-1.0.0") {
+		t.Fatal(msg)
+	}
+}
+
+func TestMortar_Invalid(t *testing.T) {
+	foo := module{name: "foo", routes: []mason.Stone{httpmortar.HandleFunc(http.MethodGet, "/items", text("foo"))}}
+	bar := module{name: "bar", routes: []mason.Stone{httpmortar.HandleFunc(http.MethodGet, "/a/{x", text("bar"))}}
+	scaffold := mason.New(httpmortar.New(nil))
+	if err := scaffold.Load(context.TODO(), foo); err != nil {
+		t.Fatal(err)
+	}
+	err := scaffold.Load(context.TODO(), bar)
+	if !errors.Is(err, httpmortar.ErrInvalidRoute) || errors.Is(err, httpmortar.ErrConflictingRoute) {
+		t.Fatal(err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "GET '/a/{x' from bar-1.0.0") || strings.Contains(msg, "foo-1.0.0") {
+		t.Fatal(msg)
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+package semver
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidVersion    error = errors.New("invalid semantic version")
+	ErrInvalidConstraint error = errors.New("invalid version constraint")
+)
+
+type (
+	// Version is a semantic version, see https://semver.org.
+	Version struct {
+		Major, Minor, Patch uint64
+		Pre                 []string
+	}
+	// Constraint is a disjunction of conjunctive comparator sets, such as ">=1.2, <2 || ^3".
+	Constraint struct {
+		sets [][]comparator
+	}
+	comparator struct {
+		op string
+		v  Version
+	}
+)
+
+// Parse parses a Version, tolerating a "v" prefix, missing minor or patch numbers, and build metadata.
+func Parse(s string) (v Version, err error) {
+	v, _, wild, err := parse(s)
+	if err == nil && wild {
+		err = ErrInvalidVersion
+	}
+	return
+}
+
+// parse parses a possibly partial Version and returns how many numeric parts were specified before any wildcard.
+func parse(s string) (v Version, n int, wild bool, err error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		for _, id := range strings.Split(s[i+1:], ".") {
+			if id == "" {
+				return v, 0, false, ErrInvalidVersion
+			}
+			v.Pre = append(v.Pre, id)
+		}
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, 0, false, ErrInvalidVersion
+	}
+	nums := [3]*uint64{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		if part == "*" || part == "x" || part == "X" {
+			if v.Pre != nil {
+				return v, 0, false, ErrInvalidVersion
+			}
+			return v, i, true, nil
+		}
+		num, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return v, 0, false, ErrInvalidVersion
+		}
+		*nums[i] = num
+	}
+	return v, len(parts), false, nil
+}
+
+// String implements fmt.Stringer.
+func (v Version) String() string {
+	s := strconv.FormatUint(v.Major, 10) + "." + strconv.FormatUint(v.Minor, 10) + "." + strconv.FormatUint(v.Patch, 10)
+	if len(v.Pre) > 0 {
+		s += "-" + strings.Join(v.Pre, ".")
+	}
+	return s
+}
+
+// Compare returns -1, 0, or 1 if v has lower, equal, or higher precedence than o.
+func (v Version) Compare(o Version) int {
+	if c := compareUint(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+	switch {
+	case len(v.Pre) == 0 && len(o.Pre) == 0:
+		return 0
+	case len(v.Pre) == 0:
+		return 1
+	case len(o.Pre) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.Pre) && i < len(o.Pre); i++ {
+		if c := compareIdentifier(v.Pre[i], o.Pre[i]); c != 0 {
+			return c
+		}
+	}
+	return compareUint(uint64(len(v.Pre)), uint64(len(o.Pre)))
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareIdentifier(a, b string) int {
+	x, errA := strconv.ParseUint(a, 10, 64)
+	y, errB := strconv.ParseUint(b, 10, 64)
+	switch {
+	case errA == nil && errB == nil:
+		return compareUint(x, y)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// ParseConstraint parses a Constraint. Comparators are separated by commas and alternatives by "||". Supported
+// operators are =, !=, >, >=, <, <=, ~ (patch updates), and ^ (compatible updates); partial versions and
+// wildcards match any version with the same prefix.
+func ParseConstraint(s string) (c Constraint, err error) {
+	for _, alt := range strings.Split(s, "||") {
+		var set []comparator
+		for _, term := range strings.Split(alt, ",") {
+			term = strings.TrimSpace(term)
+			if term == "" {
+				return c, ErrInvalidConstraint
+			}
+			cmps, err := parseComparator(term)
+			if err != nil {
+				return c, err
+			}
+			set = append(set, cmps...)
+		}
+		c.sets = append(c.sets, set)
+	}
+	return
+}
+
+// parseComparator expands a single term into primitive comparators.
+func parseComparator(term string) ([]comparator, error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", "!=", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(term, prefix) {
+			op = prefix
+			break
+		}
+	}
+	v, n, _, err := parse(term[len(op):])
+	if err != nil {
+		return nil, ErrInvalidConstraint
+	}
+	if n == 0 {
+		// wildcard matches everything
+		if op != "" && op != "=" {
+			return nil, ErrInvalidConstraint
+		}
+		return nil, nil
+	}
+	upper := next(v, n, op)
+	switch op {
+	case "~", "^":
+		return []comparator{{op: ">=", v: v}, {op: "<", v: upper}}, nil
+	case "", "=":
+		if n == 3 {
+			return []comparator{{op: "=", v: v}}, nil
+		}
+		return []comparator{{op: ">=", v: v}, {op: "<", v: upper}}, nil
+	case ">":
+		if n < 3 {
+			return []comparator{{op: ">=", v: upper}}, nil
+		}
+	case "<=":
+		if n < 3 {
+			return []comparator{{op: "<", v: upper}}, nil
+		}
+	case "!=":
+		if n < 3 {
+			return nil, ErrInvalidConstraint
+		}
+	}
+	return []comparator{{op: op, v: v}}, nil
+}
+
+// next returns the exclusive upper bound for a caret, tilde, or partial Version.
+func next(v Version, n int, op string) Version {
+	switch {
+	case n == 1, op == "^" && v.Major > 0:
+		return Version{Major: v.Major + 1}
+	case n == 2, op == "~", op == "^" && v.Minor > 0:
+		return Version{Major: v.Major, Minor: v.Minor + 1}
+	}
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+}
+
+// Check reports whether a Version satisfies the Constraint. Like npm and Masterminds, a prerelease Version only
+// satisfies a comparator set that names a prerelease of the same major, minor, and patch version, so that ">=1.2, <2"
+// does not match 2.0.0-rc.1.
+func (c Constraint) Check(v Version) bool {
+	for _, set := range c.sets {
+		ok := len(v.Pre) == 0 || allowsPre(set, v)
+		for i := 0; ok && i < len(set); i++ {
+			ok = set[i].check(v)
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsPre checks whether a comparator set names a prerelease of the same major, minor, and patch version.
+func allowsPre(set []comparator, v Version) bool {
+	for _, cmp := range set {
+		if len(cmp.v.Pre) > 0 && cmp.v.Major == v.Major && cmp.v.Minor == v.Minor && cmp.v.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+func (cmp comparator) check(v Version) bool {
+	c := v.Compare(cmp.v)
+	switch cmp.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+package semver_test
+
+import (
+	"errors"
+	"github.com/pedregon/mason/v2/internal/semver"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in, out string
+		err     error
+	}{
+		{"1.2.3", "1.2.3", nil},
+		{"v1.2.3", "1.2.3", nil},
+		{"1.2", "1.2.0", nil},
+		{"1", "1.0.0", nil},
+		{"1.2.3-rc.1+build.5", "1.2.3-rc.1", nil},
+		{"1.2.3-", "", semver.ErrInvalidVersion},
+		{"1.2.3.4", "", semver.ErrInvalidVersion},
+		{"1.x", "", semver.ErrInvalidVersion},
+		{"a.b.c", "", semver.ErrInvalidVersion},
+	}
+	for _, tt := range tests {
+		v, err := semver.Parse(tt.in)
+		if !errors.Is(err, tt.err) || (err == nil && v.String() != tt.out) {
+			t.Errorf("Parse(%q) = %s, %v", tt.in, v, err)
+		}
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		cmp  int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-rc.1", "1.0.0-beta.11", 1},
+	}
+	for _, tt := range tests {
+		a, b := mustParse(t, tt.a), mustParse(t, tt.b)
+		if cmp := a.Compare(b); cmp != tt.cmp {
+			t.Errorf("%s.Compare(%s) = %d", tt.a, tt.b, cmp)
+		}
+	}
+}
+
+func TestConstraint_Check(t *testing.T) {
+	tests := []struct {
+		constraint, version string
+		ok                  bool
+	}{
+		{"1.2.3", "1.2.3", true},
+		{"=1.2.3", "1.2.4", false},
+		{"1.2", "1.2.9", true},
+		{"1.2", "1.3.0", false},
+		{"1.x", "1.9.0", true},
+		{"*", "3.0.0", true},
+		{"!=1.2.3", "1.2.3", false},
+		{">1.2", "1.2.9", false},
+		{">1.2", "1.3.0", true},
+		{"<=1.2", "1.2.9", true},
+		{"<=1.2", "1.3.0", false},
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.3.0", false},
+		{"^1.2", "1.9.0", true},
+		{"^1.2", "2.0.0", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.4", false},
+		{">=1.2, <2", "1.10.1", true},
+		{">=1.2, <2", "2.0.0", false},
+		{"<1 || >=3", "3.1.0", true},
+		{"<1 || >=3", "2.0.0", false},
+		// prereleases
+		{"^1.2", "2.0.0-alpha", false},
+		{">=1.2, <2", "2.0.0-rc.1", false},
+		{">=1.2, <2", "1.5.0-rc.1", false},
+		{">=1.5.0-rc.1, <2", "1.5.0-rc.2", true},
+		{">=1.5.0-rc.1, <2", "1.6.0-rc.1", false},
+		{">=1.5.0-rc.1, <2", "1.5.0-beta", false},
+		{"<1 || ^2.0.0-beta", "2.0.0-rc.1", true},
+	}
+	for _, tt := range tests {
+		c, err := semver.ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q) = %v", tt.constraint, err)
+		}
+		if ok := c.Check(mustParse(t, tt.version)); ok != tt.ok {
+			t.Errorf("%q.Check(%s) = %t", tt.constraint, tt.version, ok)
+		}
+	}
+}
+
+func TestParseConstraint(t *testing.T) {
+	for _, s := range []string{"", ">=1.2,", ">*", "!=1.2", "1.2.3.4", ">=1.2.3-"} {
+		if _, err := semver.ParseConstraint(s); !errors.Is(err, semver.ErrInvalidConstraint) {
+			t.Errorf("ParseConstraint(%q) = %v", s, err)
+		}
+	}
+}
+
+func mustParse(t *testing.T, s string) semver.Version {
+	t.Helper()
+	v, err := semver.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
@@ -105,3 +105,9 @@ func (s *Stack[K]) Reset() {
 	s.errs = nil
 	return
 }
+
+func (s *Stack[K]) Values() []K {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]K(nil), s.values...)
+}
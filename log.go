@@ -0,0 +1,75 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+package mason
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogEvent creates a callback for Scaffold.SubscribeFunc that logs every Event with structured attributes. Failures
+// are logged at slog.LevelError, registration and provisioning attempts at slog.LevelDebug, and the remaining
+// phases at slog.LevelInfo.
+func LogEvent(logger *slog.Logger) func(Event) {
+	return func(e Event) {
+		level := slog.LevelInfo
+		switch {
+		case e.err != nil:
+			level = slog.LevelError
+		case e.Phase == PhaseRegistered || e.Phase == PhaseSkipped || e.Phase == PhaseProvisioning:
+			level = slog.LevelDebug
+		}
+		attrs := []slog.Attr{
+			slog.String("module", e.Name),
+			slog.String("version", e.Version),
+			slog.String("phase", string(e.Phase)),
+		}
+		if e.Duration > 0 {
+			attrs = append(attrs, slog.Duration("runtime", e.Duration))
+		}
+		if e.Attempt > 0 {
+			attrs = append(attrs, slog.Int("attempt", e.Attempt))
+		}
+		if e.Parent != (Info{}) {
+			attrs = append(attrs, slog.String("parent", e.Parent.String()))
+		}
+		if len(e.Chain) > 0 {
+			attrs = append(attrs, slog.String("chain", chain(e.Chain)))
+		}
+		if e.err != nil {
+			attrs = append(attrs, slog.Any("error", e.err))
+		}
+		logger.LogAttrs(context.Background(), level, "module "+string(e.Phase), attrs...)
+	}
+}
+
+// Logger returns a logger scoped with the Info of the Module being provisioned.
+func (c *Context) Logger() *slog.Logger {
+	logger := c.scaffold.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if current, ok := c.stack.Peek(); ok {
+		return logger.With(slog.String("module", current.Name), slog.String("version", current.Version))
+	}
+	return logger
+}
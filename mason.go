@@ -21,6 +21,13 @@
 
 package mason
 
+import "errors"
+
+var (
+	ErrUnhandledStone    error = errors.New("unhandled stone")
+	ErrUnhookUnsupported error = errors.New("mortar does not implement unhooker")
+)
+
 type (
 	// Mortar is the "glue" for mounting some API. It represents a collection of loosely coupled application logic
 	// intended to encourage inversion of control (IoC).
@@ -28,9 +35,21 @@ type (
 	// It is recommended to use an interface guard.
 	// https://caddyserver.com/docs/extending-caddy#interface-guards
 	Mortar interface {
-		// Hook mounts a Stone to some API.
+		// Hook mounts a Stone to some API. It should be all-or-nothing, such that no Stone remain mounted if it
+		// fails.
 		Hook(...Stone) error
 	}
+	// ModuleHooker is an optional Mortar interface for mounting Stone along with the Module that provides them. It
+	// is preferred over Mortar.Hook when implemented.
+	ModuleHooker interface {
+		// HookModule mounts a Stone provided by a Module to some API. Like Mortar.Hook, it should be all-or-nothing.
+		HookModule(info Info, stone ...Stone) error
+	}
+	// Unhooker is an optional Mortar interface for unmounting Stone, such as when a Module is unloaded.
+	Unhooker interface {
+		// Unhook unmounts Stone from some API.
+		Unhook(...Stone) error
+	}
 	// Stone is a "provider" that extends some API. Empty for future backwards compatibility.
 	Stone any
 )
@@ -22,19 +22,33 @@
 package mason_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"github.com/pedregon/mason/v2"
+	"log/slog"
+	"reflect"
 	"runtime/debug"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
+// defaults counts TestRegistry runs registering into the default Registry.
+var defaults int32
+
 var (
-	_ mason.Mortar = (*nopMortar)(nil)
-	_ mason.Module = (*module)(nil)
+	_ mason.Mortar    = (*nopMortar)(nil)
+	_ mason.Unhooker  = (*nopMortar)(nil)
+	_ mason.Module    = (*module)(nil)
+	_ mason.Dependent = (*static)(nil)
+	_ mason.Timeouter = (*sleepy)(nil)
+	_ mason.Starter   = (*lifecycle)(nil)
+	_ mason.Stopper   = (*lifecycle)(nil)
+	_ mason.Provider  = (*provider)(nil)
 )
 
 type (
@@ -48,6 +62,66 @@ type (
 		deps     []mason.Info
 		services []mason.Stone
 	}
+	requirer struct {
+		*module
+		dep        string
+		constraint string
+		resolved   *mason.Info
+	}
+	slow struct {
+		*module
+		delay time.Duration
+		count *int32
+	}
+	static struct {
+		*module
+		count *int32
+	}
+	failing struct {
+		*module
+		err error
+	}
+	panicking struct {
+		*module
+	}
+	optional struct {
+		*module
+		optional []mason.Info
+		loaded   *[]mason.Info
+	}
+	sleepy struct {
+		*module
+		delay   time.Duration
+		timeout time.Duration
+	}
+	flaky struct {
+		*module
+		failures *int32
+		err      error
+	}
+	lifecycle struct {
+		*module
+		mu    *sync.Mutex
+		calls *[]string
+		block bool
+	}
+	logging struct {
+		*module
+	}
+	namespaced struct {
+		*module
+		namespace string
+		loaded    *[]mason.Info
+	}
+	provider struct {
+		*module
+		provides []reflect.Type
+		provide  func(c *mason.Context) error
+	}
+	greeter interface {
+		Greet() string
+	}
+	english struct{}
 )
 
 func (mort *nopMortar) Hook(s ...mason.Stone) error {
@@ -57,6 +131,20 @@ func (mort *nopMortar) Hook(s ...mason.Stone) error {
 	return nil
 }
 
+func (mort *nopMortar) Unhook(s ...mason.Stone) error {
+	mort.mu.Lock()
+	defer mort.mu.Unlock()
+	for _, stone := range s {
+		for i := range mort.services {
+			if mort.services[i] == stone {
+				mort.services = append(mort.services[:i:i], mort.services[i+1:]...)
+				break
+			}
+		}
+	}
+	return nil
+}
+
 func (mort *nopMortar) list() []mason.Stone {
 	mort.mu.RLock()
 	defer mort.mu.RUnlock()
@@ -81,11 +169,115 @@ func (mod module) Provision(c *mason.Context) (err error) {
 	return
 }
 
+func (mod lifecycle) record(call string) {
+	mod.mu.Lock()
+	defer mod.mu.Unlock()
+	*mod.calls = append(*mod.calls, call+" "+mod.name)
+}
+
+func (mod lifecycle) Start(_ context.Context) error {
+	mod.record("start")
+	return nil
+}
+
+func (mod lifecycle) Stop(ctx context.Context) error {
+	if mod.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	mod.record("stop")
+	return nil
+}
+
+func (mod requirer) Provision(c *mason.Context) (err error) {
+	*mod.resolved, err = c.Require(mod.dep, mod.constraint)
+	return
+}
+
+func (mod slow) Provision(c *mason.Context) error {
+	atomic.AddInt32(mod.count, 1)
+	time.Sleep(mod.delay)
+	return mod.module.Provision(c)
+}
+
+func (mod static) Dependencies() []mason.Info {
+	return mod.deps
+}
+
+func (mod static) Provision(c *mason.Context) error {
+	atomic.AddInt32(mod.count, 1)
+	return c.Hook(mod.services...)
+}
+
+func (mod failing) Provision(c *mason.Context) error {
+	if err := mod.module.Provision(c); err != nil {
+		return err
+	}
+	return mod.err
+}
+
+func (mod panicking) Provision(c *mason.Context) error {
+	if err := mod.module.Provision(c); err != nil {
+		return err
+	}
+	panic("oops")
+}
+
+func (mod optional) Provision(c *mason.Context) (err error) {
+	*mod.loaded, err = c.LoadOptional(mod.optional...)
+	return
+}
+
+func (mod sleepy) Timeout() time.Duration {
+	return mod.timeout
+}
+
+func (mod sleepy) Provision(c *mason.Context) error {
+	select {
+	case <-time.After(mod.delay):
+	case <-c.Done():
+		return c.Err()
+	}
+	return mod.module.Provision(c)
+}
+
+func (mod logging) Provision(c *mason.Context) error {
+	c.Logger().Info("hello")
+	return mod.module.Provision(c)
+}
+
+func (mod namespaced) Provision(c *mason.Context) (err error) {
+	*mod.loaded, err = c.LoadNamespace(mod.namespace)
+	return
+}
+
+func (mod provider) Provides() []reflect.Type {
+	return mod.provides
+}
+
+func (mod provider) Provision(c *mason.Context) error {
+	if err := mod.module.Provision(c); err != nil {
+		return err
+	}
+	return mod.provide(c)
+}
+
+func (english) Greet() string {
+	return "hello"
+}
+
+func (mod flaky) Provision(c *mason.Context) error {
+	if atomic.AddInt32(mod.failures, -1) >= 0 {
+		return mod.err
+	}
+	return mod.module.Provision(c)
+}
+
 func log(t *testing.T, s *mason.Scaffold, ch <-chan mason.Event) {
 	for e := range ch {
 		if err := e.Err(); err != nil {
-			t.Logf("[Mason] ERROR msg='failed to load' info=%s err='%s'", e.Info, err)
-		} else {
+			t.Logf("[Mason] ERROR msg='failed to load' phase=%s info=%s err='%s'", e.Phase, e.Info, err)
+		} else if e.Phase == mason.PhaseProvisioned {
 			t.Logf("[Mason] INFO msg='loaded' module=%s, runtime=%s", e.Info, s.Stat(e.Info))
 		}
 	}
@@ -284,3 +476,1215 @@ func TestScaffold_Append(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestScaffold_Shutdown(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	// discover
+	baz := lifecycle{module: &module{name: "baz", version: "1.0.0"}, mu: &mu, calls: &calls}
+	bar := lifecycle{module: &module{name: "bar", version: "1.0.0"}, mu: &mu, calls: &calls}
+	bar.deps = append(bar.deps, baz.Info())
+	foo := lifecycle{module: &module{name: "foo", version: "1.0.0"}, mu: &mu, calls: &calls}
+	foo.deps = append(foo.deps, bar.Info(), baz.Info())
+	// register
+	modules := []mason.Module{baz, foo, bar}
+	// construct
+	scaffold := mason.New(&nopMortar{})
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	// hook
+	if err := load(ctx, cancel, scaffold, modules...); err != nil {
+		t.Fatal(err)
+	}
+	if err := scaffold.Start(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if err := scaffold.Shutdown(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	expected := "start baz, start bar, start foo, stop foo, stop bar, stop baz"
+	if actual := strings.Join(calls, ", "); actual != expected {
+		t.Fatalf("expected [%s], got [%s]", expected, actual)
+	}
+}
+
+func TestScaffold_ShutdownDeadline(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	// discover
+	bar := lifecycle{module: &module{name: "bar", version: "1.0.0"}, mu: &mu, calls: &calls}
+	foo := lifecycle{module: &module{name: "foo", version: "1.0.0"}, mu: &mu, calls: &calls, block: true}
+	foo.deps = append(foo.deps, bar.Info())
+	// construct
+	scaffold := mason.New(&nopMortar{})
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	// hook
+	if err := load(ctx, cancel, scaffold, foo, bar); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel = context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	err := scaffold.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+	if !strings.Contains(err.Error(), "foo-1.0.0") || !strings.Contains(err.Error(), "bar-1.0.0") {
+		t.Fatal(err)
+	}
+	t.Log(err)
+}
+
+func TestContext_Require(t *testing.T) {
+	// discover
+	var resolved mason.Info
+	foo := requirer{module: &module{name: "foo", version: "1.0.0"}, dep: "bar", constraint: ">=1.2, <2", resolved: &resolved}
+	modules := []mason.Module{
+		foo,
+		&module{name: "bar", version: "1.1.9"},
+		&module{name: "bar", version: "1.2.0"},
+		&module{name: "bar", version: "1.10.1"},
+		&module{name: "bar", version: "2.0.0-rc.1"},
+		&module{name: "bar", version: "2.0.0"},
+	}
+	// construct
+	scaffold := mason.New(&nopMortar{})
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	// hook
+	if err := load(ctx, cancel, scaffold, modules...); err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Version != "1.10.1" {
+		t.Fatalf("expected bar-1.10.1, got %s", resolved)
+	}
+}
+
+func TestConstraintError(t *testing.T) {
+	// discover
+	var resolved mason.Info
+	foo := requirer{module: &module{name: "foo", version: "1.0.0"}, dep: "bar", constraint: "^3", resolved: &resolved}
+	modules := []mason.Module{foo, &module{name: "bar", version: "2.0.0"}, &module{name: "bar", version: "1.0.0"}}
+	// construct
+	scaffold := mason.New(&nopMortar{})
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	// hook
+	err := load(ctx, cancel, scaffold, modules...)
+	var constraintErr *mason.ConstraintError
+	if !errors.As(err, &constraintErr) || !errors.Is(err, mason.ErrMissingDependency) {
+		t.Fatal(err)
+	}
+	if len(constraintErr.Candidates) != 2 || constraintErr.Candidates[0].Version != "1.0.0" {
+		t.Fatal(constraintErr.Candidates)
+	}
+	t.Log(err)
+}
+
+func TestParallel(t *testing.T) {
+	const delay = 50 * time.Millisecond
+	var count int32
+	// discover
+	db := slow{module: &module{name: "db", version: "1.0.0"}, delay: delay, count: &count}
+	modules := []mason.Module{db}
+	for _, name := range []string{"foo", "bar", "baz", "qux"} {
+		mod := slow{module: &module{name: name, version: "1.0.0"}, delay: delay, count: &count}
+		mod.deps = append(mod.deps, db.Info())
+		modules = append(modules, mod)
+	}
+	// construct
+	scaffold := mason.New(&nopMortar{}, mason.Parallel(len(modules)))
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	// hook
+	start := time.Now()
+	if err := load(ctx, cancel, scaffold, modules...); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed >= time.Duration(len(modules))*delay {
+		t.Fatalf("expected concurrent provisioning, took %s", elapsed)
+	}
+	if int(count) != len(modules) {
+		t.Fatalf("expected %d provisions, got %d", len(modules), count)
+	}
+	if len(mason.Graph(scaffold)) != len(modules)-1 {
+		t.Fatal(mason.Graph(scaffold))
+	}
+}
+
+func TestParallelCircularDependency(t *testing.T) {
+	var count int32
+	// discover
+	baz := slow{module: &module{name: "baz", version: "1.0.0"}, delay: time.Millisecond, count: &count}
+	baz.deps = append(baz.deps, mason.Info{Name: "foo", Version: "1.0.0"})
+	bar := slow{module: &module{name: "bar", version: "1.0.0"}, delay: time.Millisecond, count: &count}
+	bar.deps = append(bar.deps, baz.Info())
+	foo := slow{module: &module{name: "foo", version: "1.0.0"}, delay: time.Millisecond, count: &count}
+	foo.deps = append(foo.deps, bar.Info())
+	// construct
+	scaffold := mason.New(&nopMortar{}, mason.Parallel(3))
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	// hook
+	err := load(ctx, cancel, scaffold, foo, bar, baz)
+	if !errors.Is(err, mason.ErrCircularDependency) {
+		t.Fatal(err)
+	}
+	t.Log(err)
+}
+
+func TestScaffold_Plan(t *testing.T) {
+	var count int32
+	// discover
+	baz := static{module: &module{name: "baz", version: "1.0.0"}, count: &count}
+	bar := static{module: &module{name: "bar", version: "1.0.0"}, count: &count}
+	bar.deps = append(bar.deps, baz.Info())
+	foo := static{module: &module{name: "foo", version: "1.0.0"}, count: &count}
+	foo.deps = append(foo.deps, bar.Info(), baz.Info())
+	// construct
+	scaffold := mason.New(&nopMortar{})
+	order, err := scaffold.Plan(foo, bar, baz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := fmt.Sprint(order); actual != "[baz-1.0.0 bar-1.0.0 foo-1.0.0]" {
+		t.Fatal(actual)
+	}
+	if count != 0 || mason.Len(scaffold) != 0 {
+		t.FailNow()
+	}
+	// hook
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	if err = load(ctx, cancel, scaffold, foo, bar, baz); err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 || len(mason.Graph(scaffold)) != 3 {
+		t.Fatal(mason.Graph(scaffold))
+	}
+}
+
+func TestPlanError(t *testing.T) {
+	var count int32
+	// discover
+	baz := static{module: &module{name: "baz", version: "1.0.0"}, count: &count}
+	baz.deps = append(baz.deps, mason.Info{Name: "qux", Version: "1.0.0"})
+	bar := static{module: &module{name: "bar", version: "1.0.0"}, count: &count}
+	bar.deps = append(bar.deps, mason.Info{Name: "foo", Version: "1.0.0"})
+	foo := static{module: &module{name: "foo", version: "1.0.0"}, count: &count}
+	foo.deps = append(foo.deps, bar.Info(), baz.Info())
+	// construct
+	scaffold := mason.New(&nopMortar{})
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	// hook
+	err := load(ctx, cancel, scaffold, foo, bar, baz)
+	if !errors.Is(err, mason.ErrMissingDependency) || !errors.Is(err, mason.ErrCircularDependency) {
+		t.Fatal(err)
+	}
+	var planErr *mason.PlanError
+	if !errors.As(err, &planErr) || len(planErr.Missing) != 1 || len(planErr.Cycles) != 1 {
+		t.Fatal(err)
+	}
+	if actual := fmt.Sprint(planErr.Cycles[0]); actual != "[foo-1.0.0 bar-1.0.0 foo-1.0.0]" {
+		t.Fatal(actual)
+	}
+	if count != 0 {
+		t.FailNow()
+	}
+	t.Log(err)
+}
+
+func TestLoadError(t *testing.T) {
+	// discover
+	baz := &module{name: "baz", version: "1.0.0"}
+	baz.deps = append(baz.deps, mason.Info{Name: "foo", Version: "1.0.0"})
+	bar := &module{name: "bar", version: "1.0.0"}
+	bar.deps = append(bar.deps, baz.Info())
+	foo := &module{name: "foo", version: "1.0.0"}
+	foo.deps = append(foo.deps, bar.Info())
+	qux := &module{name: "qux", version: "1.0.0"}
+	qux.deps = append(qux.deps, mason.Info{Name: "quux", Version: "1.0.0"})
+	for _, tc := range []struct {
+		modules    []mason.Module
+		sentinel   error
+		info       mason.Info
+		dependency mason.Info
+		chain      string
+	}{
+		{
+			modules:    []mason.Module{foo, bar, baz},
+			sentinel:   mason.ErrCircularDependency,
+			info:       baz.Info(),
+			dependency: foo.Info(),
+			chain:      "[foo-1.0.0 bar-1.0.0 baz-1.0.0 foo-1.0.0]",
+		},
+		{
+			modules:    []mason.Module{qux},
+			sentinel:   mason.ErrMissingDependency,
+			info:       qux.Info(),
+			dependency: mason.Info{Name: "quux", Version: "1.0.0"},
+			chain:      "[qux-1.0.0 quux-1.0.0]",
+		},
+	} {
+		// construct
+		scaffold := mason.New(&nopMortar{})
+		ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+		// hook
+		err := load(ctx, cancel, scaffold, tc.modules...)
+		var loadErr *mason.LoadError
+		if !errors.Is(err, tc.sentinel) || !errors.As(err, &loadErr) {
+			t.Fatal(err)
+		}
+		if loadErr.Info != tc.info || loadErr.Dependency != tc.dependency || fmt.Sprint(loadErr.Chain) != tc.chain {
+			t.Fatal(err)
+		}
+		t.Log(err)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	// discover
+	baz := &module{name: "baz", version: "1.0.0"}
+	bar := &module{name: "bar", version: "1.0.0"}
+	bar.deps = append(bar.deps, baz.Info())
+	foo := &module{name: "foo", version: "1.0.0"}
+	foo.deps = append(foo.deps, bar.Info(), baz.Info())
+	qux := &module{name: "qux", version: "1.0.0"}
+	qux.deps = append(qux.deps, baz.Info())
+	quux := &module{name: "quux", version: "1.0.0"}
+	// construct
+	scaffold := mason.New(&nopMortar{})
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	// hook
+	if err := load(ctx, cancel, scaffold, quux, qux, foo, bar, baz); err != nil {
+		t.Fatal(err)
+	}
+	snap := scaffold.Snapshot()
+	for _, tc := range []struct {
+		actual   []mason.Info
+		expected string
+	}{
+		{snap.DependenciesOf(foo.Info(), false), "[bar-1.0.0 baz-1.0.0]"},
+		{snap.DependenciesOf(qux.Info(), true), "[baz-1.0.0]"},
+		{snap.DependentsOf(baz.Info(), false), "[bar-1.0.0 foo-1.0.0 qux-1.0.0]"},
+		{snap.DependentsOf(bar.Info(), true), "[foo-1.0.0]"},
+		{snap.Roots(), "[foo-1.0.0 quux-1.0.0 qux-1.0.0]"},
+		{snap.Leaves(), "[baz-1.0.0 quux-1.0.0]"},
+		{snap.Path(foo.Info(), baz.Info()), "[foo-1.0.0 baz-1.0.0]"},
+		{snap.Path(baz.Info(), foo.Info()), "[]"},
+		{snap.TopoOrder(), "[baz-1.0.0 bar-1.0.0 foo-1.0.0 quux-1.0.0 qux-1.0.0]"},
+	} {
+		if actual := fmt.Sprint(tc.actual); actual != tc.expected {
+			t.Errorf("expected %s, got %s", tc.expected, actual)
+		}
+	}
+}
+
+func TestScaffold_Reload(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	// discover
+	baz := lifecycle{module: &module{name: "baz", version: "1.0.0", services: []mason.Stone{"baz"}}, mu: &mu, calls: &calls}
+	bar := lifecycle{module: &module{name: "bar", version: "1.0.0", services: []mason.Stone{"bar"}}, mu: &mu, calls: &calls}
+	bar.deps = append(bar.deps, baz.Info())
+	foo := lifecycle{module: &module{name: "foo", version: "1.0.0", services: []mason.Stone{"foo"}}, mu: &mu, calls: &calls}
+	foo.deps = append(foo.deps, bar.Info())
+	// construct
+	mort := &nopMortar{}
+	scaffold := mason.New(mort)
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	// hook
+	if err := load(ctx, cancel, scaffold, foo, bar, baz); err != nil {
+		t.Fatal(err)
+	}
+	if err := scaffold.Start(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if err := scaffold.Unload(context.TODO(), bar.Info(), false); !errors.Is(err, mason.ErrLoadedDependents) {
+		t.Fatal(err)
+	}
+	if err := scaffold.Unload(context.TODO(), bar.Info(), true); err != nil {
+		t.Fatal(err)
+	}
+	if mason.Len(scaffold) != 1 || fmt.Sprint(mort.list()) != "[baz]" {
+		t.Fatal(mort.list())
+	}
+	// reload
+	if err := scaffold.Load(context.TODO(), foo, bar); err != nil {
+		t.Fatal(err)
+	}
+	if err := scaffold.Start(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	calls = nil
+	reloaded := lifecycle{module: &module{name: "bar", version: "1.0.0", services: []mason.Stone{"bar2"}}, mu: &mu, calls: &calls}
+	reloaded.deps = append(reloaded.deps, baz.Info())
+	if err := scaffold.Reload(context.TODO(), reloaded); err != nil {
+		t.Fatal(err)
+	}
+	if mason.Len(scaffold) != 3 || fmt.Sprint(mort.list()) != "[baz bar2 foo]" {
+		t.Fatal(mort.list())
+	}
+	if actual := strings.Join(calls, ", "); actual != "stop foo, stop bar, start bar, start foo" {
+		t.Fatal(actual)
+	}
+	// dangling
+	errFailed := errors.New("failed")
+	qux := failing{module: &module{name: "qux", version: "1.0.0", deps: []mason.Info{baz.Info()}}, err: errFailed}
+	if err := scaffold.Load(context.TODO(), qux); !errors.Is(err, errFailed) {
+		t.Fatal(err)
+	}
+	if err := scaffold.Unload(context.TODO(), foo.Info(), true); err != nil {
+		t.Fatal(err)
+	}
+	if err := scaffold.Unload(context.TODO(), baz.Info(), true); err != nil {
+		t.Fatal(err)
+	}
+	for _, edge := range scaffold.Snapshot().Edges {
+		t.Fatal(edge)
+	}
+}
+
+func TestTransactional(t *testing.T) {
+	errFailed := errors.New("failed")
+	// discover
+	baz := failing{module: &module{name: "baz", version: "1.0.0", services: []mason.Stone{"baz"}}, err: errFailed}
+	bar := &module{name: "bar", version: "1.0.0", services: []mason.Stone{"bar"}}
+	foo := &module{name: "foo", version: "1.0.0", services: []mason.Stone{"foo"}}
+	foo.deps = append(foo.deps, bar.Info(), baz.Info())
+	qux := &module{name: "qux", version: "1.0.0", services: []mason.Stone{"qux"}}
+	// construct
+	mort := &nopMortar{}
+	scaffold := mason.New(mort, mason.Transactional())
+	if err := scaffold.Load(context.TODO(), qux); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	// hook
+	if err := load(ctx, cancel, scaffold, foo, bar, baz); !errors.Is(err, errFailed) {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(mort.list()) != "[qux]" {
+		t.Fatal(mort.list())
+	}
+	for _, rel := range mason.Graph(scaffold) {
+		t.Fatal(rel)
+	}
+	for _, node := range scaffold.Snapshot().Nodes {
+		if node.Loaded != (node.Info == qux.Info()) {
+			t.Fatal(node)
+		}
+	}
+	// nested
+	inner := &module{name: "inner", version: "1.0.0", services: []mason.Stone{"inner"}}
+	outer := provider{module: &module{name: "outer", version: "1.0.0", services: []mason.Stone{"outer"}}}
+	outer.provide = func(c *mason.Context) error {
+		if err := mason.New(c).Load(c, inner); err != nil {
+			return err
+		}
+		return errFailed
+	}
+	if err := scaffold.Load(context.TODO(), outer); !errors.Is(err, errFailed) {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(mort.list()) != "[qux]" {
+		t.Fatal(mort.list())
+	}
+	// unsupported
+	scaffold = mason.New(mason.NewRouter(), mason.Transactional())
+	if err := scaffold.Load(context.TODO(), qux); !errors.Is(err, mason.ErrUnhookUnsupported) {
+		t.Fatal(err)
+	}
+}
+
+func TestPanicError(t *testing.T) {
+	// discover
+	bar := panicking{module: &module{name: "bar", version: "1.0.0"}}
+	foo := &module{name: "foo", version: "1.0.0"}
+	foo.deps = append(foo.deps, bar.Info())
+	baz := &module{name: "baz", version: "1.0.0"}
+	// observer
+	ch := make(chan mason.Event, 16)
+	// construct
+	scaffold := mason.New(&nopMortar{}, mason.OnLoad(ch), mason.Parallel(2))
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	// hook
+	err := load(ctx, cancel, scaffold, foo, bar)
+	var panicErr *mason.PanicError
+	if !errors.As(err, &panicErr) || panicErr.Info != bar.Info() || panicErr.Value != "oops" || len(panicErr.Stack) == 0 {
+		t.Fatal(err)
+	}
+	var published bool
+	for len(ch) > 0 {
+		if e := <-ch; errors.As(e.Err(), &panicErr) {
+			published = true
+		}
+	}
+	if !published {
+		t.FailNow()
+	}
+	if err = scaffold.Load(context.TODO(), baz); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecoverOption(t *testing.T) {
+	defer func() {
+		r := recover()
+		if e, ok := r.(*mason.PanicError); !ok || e.Value != "oops" {
+			t.Fatal(r)
+		}
+	}()
+	// discover
+	foo := panicking{module: &module{name: "foo", version: "1.0.0"}}
+	// construct
+	scaffold := mason.New(&nopMortar{}, mason.RecoverOption(false))
+	// hook
+	_ = scaffold.Load(context.TODO(), foo)
+	t.FailNow()
+}
+
+func TestContinueOnError(t *testing.T) {
+	errFailed := errors.New("failed")
+	// discover
+	bar := failing{module: &module{name: "bar", version: "1.0.0"}, err: errFailed}
+	foo := &module{name: "foo", version: "1.0.0"}
+	foo.deps = append(foo.deps, bar.Info())
+	quux := &module{name: "quux", version: "1.0.0"}
+	quux.deps = append(quux.deps, foo.Info())
+	qux := &module{name: "qux", version: "1.0.0"}
+	baz := &module{name: "baz", version: "1.0.0"}
+	baz.deps = append(baz.deps, qux.Info())
+	for _, workers := range []int{1, 4} {
+		// construct
+		scaffold := mason.New(&nopMortar{}, mason.ContinueOnError(), mason.Parallel(workers))
+		ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+		// hook
+		err := load(ctx, cancel, scaffold, quux, foo, bar, baz, qux)
+		if !errors.Is(err, errFailed) {
+			t.Fatal(err)
+		}
+		var joined interface{ Unwrap() []error }
+		if !errors.As(err, &joined) {
+			t.Fatal(err)
+		}
+		var failed []string
+		for _, e := range joined.Unwrap() {
+			var loadErr *mason.LoadError
+			if !errors.As(e, &loadErr) || !errors.Is(e, errFailed) {
+				t.Fatal(e)
+			}
+			failed = append(failed, loadErr.Info.String())
+		}
+		if actual := strings.Join(failed, ", "); actual != "quux-1.0.0, foo-1.0.0, bar-1.0.0" {
+			t.Fatal(actual)
+		}
+		for _, node := range scaffold.Snapshot().Nodes {
+			if node.Loaded != (node.Info == baz.Info() || node.Info == qux.Info()) {
+				t.Fatal(node)
+			}
+		}
+		t.Log(err)
+	}
+}
+
+func TestContinueOnError_Plan(t *testing.T) {
+	var count int32
+	// discover
+	broken := static{module: &module{name: "broken", version: "1.0.0", deps: []mason.Info{{Name: "missing", Version: "1.0.0"}}}, count: &count}
+	dependent := static{module: &module{name: "dependent", version: "1.0.0", deps: []mason.Info{broken.Info()}}, count: &count}
+	a := static{module: &module{name: "a", version: "1.0.0", deps: []mason.Info{{Name: "b", Version: "1.0.0"}}}, count: &count}
+	b := static{module: &module{name: "b", version: "1.0.0", deps: []mason.Info{a.Info()}}, count: &count}
+	good := static{module: &module{name: "good", version: "1.0.0"}, count: &count}
+	for _, workers := range []int{1, 4} {
+		count = 0
+		// construct
+		scaffold := mason.New(&nopMortar{}, mason.ContinueOnError(), mason.Parallel(workers))
+		ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+		// hook
+		err := load(ctx, cancel, scaffold, dependent, broken, a, b, good)
+		if !errors.Is(err, mason.ErrMissingDependency) || !errors.Is(err, mason.ErrCircularDependency) {
+			t.Fatal(err)
+		}
+		var states []string
+		for _, st := range scaffold.Modules() {
+			states = append(states, fmt.Sprintf("%s=%s", st.Info.Name, st.State))
+		}
+		if actual := strings.Join(states, ", "); actual != "a=failed, b=failed, broken=failed, dependent=failed, good=loaded" {
+			t.Fatal(actual)
+		}
+		if count != 1 {
+			t.Fatal(count)
+		}
+		t.Log(err)
+	}
+}
+
+func TestContext_LoadOptional(t *testing.T) {
+	var loaded []mason.Info
+	// discover
+	bar := &module{name: "bar", version: "1.0.0"}
+	foo := optional{module: &module{name: "foo", version: "1.0.0"}, loaded: &loaded}
+	foo.optional = append(foo.optional, mason.Info{Name: "metrics", Version: "1.0.0"}, bar.Info())
+	// construct
+	scaffold := mason.New(&nopMortar{})
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	// hook
+	if err := load(ctx, cancel, scaffold, foo, bar); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(loaded) != "[bar-1.0.0]" {
+		t.Fatal(loaded)
+	}
+	graph := mason.Graph(scaffold)
+	if len(graph) != 1 || graph[0].String() != "bar-1.0.0 <= foo-1.0.0 (optional)" {
+		t.Fatal(graph)
+	}
+	// circular
+	baz := &module{name: "baz", version: "1.0.0"}
+	baz.deps = append(baz.deps, mason.Info{Name: "qux", Version: "1.0.0"})
+	qux := optional{module: &module{name: "qux", version: "1.0.0"}, loaded: &loaded}
+	qux.optional = append(qux.optional, baz.Info())
+	ctx, cancel = context.WithTimeout(context.TODO(), time.Second)
+	if err := load(ctx, cancel, scaffold, baz, qux); !errors.Is(err, mason.ErrCircularDependency) {
+		t.Fatal(err)
+	}
+}
+
+func TestProvisionTimeout(t *testing.T) {
+	// discover
+	bar := sleepy{module: &module{name: "bar", version: "1.0.0"}, delay: time.Second, timeout: 10 * time.Millisecond}
+	foo := &module{name: "foo", version: "1.0.0"}
+	foo.deps = append(foo.deps, bar.Info())
+	// construct
+	scaffold := mason.New(&nopMortar{})
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	// hook
+	err := load(ctx, cancel, scaffold, foo, bar)
+	var loadErr *mason.LoadError
+	if !errors.Is(err, mason.ErrProvisionTimeout) || !errors.As(err, &loadErr) || loadErr.Info != bar.Info() {
+		t.Fatal(err)
+	}
+	t.Log(err)
+	// override
+	bar.delay = 20 * time.Millisecond
+	scaffold = mason.New(&nopMortar{}, mason.TimeoutOption(bar.Info(), 0), mason.TimeoutOption(foo.Info(), time.Second))
+	ctx, cancel = context.WithTimeout(context.TODO(), 5*time.Second)
+	if err = load(ctx, cancel, scaffold, foo, bar); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRetryOption(t *testing.T) {
+	errFlaky := errors.New("flaky")
+	failures := int32(2)
+	// discover
+	foo := flaky{module: &module{name: "foo", version: "1.0.0", services: []mason.Stone{"foo"}}, failures: &failures, err: errFlaky}
+	// observer
+	ch := make(chan mason.Event, 16)
+	// construct
+	mort := &nopMortar{}
+	policy := mason.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, Jitter: 0.5}
+	scaffold := mason.New(mort, mason.OnLoad(ch), mason.RetryOption(policy))
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	// hook
+	if err := load(ctx, cancel, scaffold, foo); err != nil {
+		t.Fatal(err)
+	}
+	var attempts []string
+	for len(ch) > 0 {
+		e := <-ch
+		if e.Phase == mason.PhaseFailed && (e.Duration < 0 || e.Duration > time.Second) {
+			t.Fatalf("attempt %d took %s", e.Attempt, e.Duration)
+		}
+		if e.Phase == mason.PhaseFailed || e.Phase == mason.PhaseProvisioned {
+			attempts = append(attempts, fmt.Sprintf("%d:%v", e.Attempt, e.Err()))
+		}
+	}
+	if actual := strings.Join(attempts, ", "); actual != "1:flaky, 2:flaky, 3:<nil>" {
+		t.Fatal(actual)
+	}
+	if fmt.Sprint(mort.list()) != "[foo]" {
+		t.Fatal(mort.list())
+	}
+	// classifier
+	failures = 2
+	policy.Retryable = func(err error) bool {
+		return !errors.Is(err, errFlaky)
+	}
+	scaffold = mason.New(mort, mason.RetryOption(policy, foo.Info()))
+	ctx, cancel = context.WithTimeout(context.TODO(), time.Second)
+	if err := load(ctx, cancel, scaffold, foo); !errors.Is(err, errFlaky) || failures != 1 {
+		t.Fatal(err)
+	}
+}
+
+func TestEvent(t *testing.T) {
+	// discover
+	bar := &module{name: "bar", version: "1.0.0"}
+	foo := &module{name: "foo", version: "1.0.0"}
+	foo.deps = append(foo.deps, bar.Info())
+	// observer
+	ch := make(chan mason.Event, 16)
+	// construct
+	scaffold := mason.New(&nopMortar{}, mason.OnLoad(ch))
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	// hook
+	if err := load(ctx, cancel, scaffold, foo, bar); err != nil {
+		t.Fatal(err)
+	}
+	var timeline []string
+	provisioned := make(map[mason.Info]time.Time)
+	for len(ch) > 0 {
+		e := <-ch
+		if e.Time.IsZero() || e.Time.Add(e.Duration).After(time.Now()) {
+			t.Fatal(e)
+		}
+		if e.Phase == mason.PhaseProvisioned {
+			provisioned[e.Info] = e.Time
+		}
+		timeline = append(timeline, fmt.Sprintf("%s %s <= %s (%v)", e.Phase, e.Info, e.Parent, e.Chain))
+	}
+	expected := []string{
+		"registered foo-1.0.0 <= - ([])",
+		"registered bar-1.0.0 <= - ([])",
+		"provisioning foo-1.0.0 <= - ([foo-1.0.0])",
+		"provisioning bar-1.0.0 <= foo-1.0.0 ([foo-1.0.0 bar-1.0.0])",
+		"provisioned bar-1.0.0 <= foo-1.0.0 ([foo-1.0.0 bar-1.0.0])",
+		"provisioned foo-1.0.0 <= - ([foo-1.0.0])",
+	}
+	if actual := strings.Join(timeline, "\n"); actual != strings.Join(expected, "\n") {
+		t.Fatal(actual)
+	}
+	// foo started provisioning before its dependency bar
+	if provisioned[foo.Info()].After(provisioned[bar.Info()]) {
+		t.Fatal(provisioned)
+	}
+}
+
+func TestScaffold_Subscribe(t *testing.T) {
+	// discover
+	bar := &module{name: "bar", version: "1.0.0"}
+	foo := &module{name: "foo", version: "1.0.0"}
+	foo.deps = append(foo.deps, bar.Info())
+	// construct
+	scaffold := mason.New(&nopMortar{})
+	// observers
+	var (
+		mu     sync.Mutex
+		phases []mason.Phase
+	)
+	callback := scaffold.SubscribeFunc(func(e mason.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		phases = append(phases, e.Phase)
+	})
+	absent := scaffold.Subscribe(make(chan mason.Event), mason.Buffered(2, mason.OverflowDropNewest))
+	ch := make(chan mason.Event)
+	latest := scaffold.Subscribe(ch, mason.Buffered(1, mason.OverflowDropOldest))
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	// hook
+	if err := load(ctx, cancel, scaffold, foo, bar); err != nil {
+		t.Fatal(err)
+	}
+	if len(phases) != 6 {
+		t.Fatal(phases)
+	}
+	if absent.Dropped() == 0 {
+		t.Fatal("expected dropped events")
+	}
+	if e := <-ch; e.Info != foo.Info() && e.Info != bar.Info() {
+		t.Fatal(e)
+	}
+	// unsubscribe
+	callback.Unsubscribe()
+	absent.Unsubscribe()
+	latest.Unsubscribe()
+	if err := scaffold.Unload(context.TODO(), foo.Info(), false); err != nil {
+		t.Fatal(err)
+	}
+	if len(phases) != 6 {
+		t.Fatal(phases)
+	}
+	// concurrent
+	baz := &module{name: "baz", version: "1.0.0"}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- scaffold.Load(context.TODO(), baz)
+	}()
+	for i := 0; i < 8; i++ {
+		defer scaffold.Subscribe(make(chan mason.Event, 8)).Unsubscribe()
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoggerOption(t *testing.T) {
+	// discover
+	bar := logging{module: &module{name: "bar", version: "1.0.0"}}
+	foo := &module{name: "foo", version: "1.0.0"}
+	foo.deps = append(foo.deps, bar.Info(), mason.Info{Name: "baz", Version: "1.0.0"})
+	// observer
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "runtime" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	// construct
+	scaffold := mason.New(&nopMortar{}, mason.LoggerOption(logger))
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	// hook
+	if err := load(ctx, cancel, scaffold, foo, bar); !errors.Is(err, mason.ErrMissingDependency) {
+		t.Fatal(err)
+	}
+	expected := []string{
+		`level=INFO msg=hello module=bar version=1.0.0`,
+		`level=INFO msg="module provisioned" module=bar version=1.0.0 phase=provisioned attempt=1 parent=foo-1.0.0 chain="foo-1.0.0 -> bar-1.0.0"`,
+		`level=ERROR msg="module failed" module=baz version=1.0.0 phase=failed parent=foo-1.0.0 chain="foo-1.0.0 -> baz-1.0.0" error="foo-1.0.0 failed to load dependency baz-1.0.0 (foo-1.0.0 -> baz-1.0.0), missing module dependency"`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(buf.String(), line) {
+			t.Fatal(buf.String())
+		}
+	}
+}
+
+func TestScaffold_State(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	errBaz := errors.New("baz")
+	// discover
+	bar := lifecycle{module: &module{name: "bar", version: "1.0.0"}, mu: &mu, calls: &calls}
+	foo := lifecycle{module: &module{name: "foo", version: "1.0.0"}, mu: &mu, calls: &calls}
+	foo.deps = append(foo.deps, bar.Info())
+	baz := failing{module: &module{name: "baz", version: "1.0.0"}, err: errBaz}
+	qux := &module{name: "qux", version: "1.0.0"}
+	// construct
+	scaffold := mason.New(&nopMortar{}, mason.ContinueOnError(), mason.SkipOption(func(info mason.Info) bool {
+		return info.Name == "qux"
+	}))
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	// hook
+	if err := load(ctx, cancel, scaffold, foo, bar, baz, qux); !errors.Is(err, errBaz) {
+		t.Fatal(err)
+	}
+	states := func() string {
+		var list []string
+		for _, st := range scaffold.Modules() {
+			list = append(list, fmt.Sprintf("%s=%s", st.Info, st.State))
+		}
+		return strings.Join(list, ", ")
+	}
+	if actual := states(); actual != "bar-1.0.0=loaded, baz-1.0.0=failed, foo-1.0.0=loaded, qux-1.0.0=skipped" {
+		t.Fatal(actual)
+	}
+	if state := scaffold.State(mason.Info{Name: "quux", Version: "1.0.0"}); state != mason.StateUnknown {
+		t.Fatal(state)
+	}
+	// lifecycle
+	if err := scaffold.Start(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if state := scaffold.State(foo.Info()); state != mason.StateStarted {
+		t.Fatal(state)
+	}
+	if err := scaffold.Shutdown(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if err := scaffold.Unload(context.TODO(), foo.Info(), false); err != nil {
+		t.Fatal(err)
+	}
+	if actual := states(); actual != "bar-1.0.0=stopped, baz-1.0.0=failed, foo-1.0.0=unloaded, qux-1.0.0=skipped" {
+		t.Fatal(actual)
+	}
+	for _, st := range scaffold.Modules() {
+		switch st.Info {
+		case baz.Info():
+			if !errors.Is(st.Err, errBaz) {
+				t.Fatal(st.Err)
+			}
+		case foo.Info():
+			if len(st.Dependencies) != 1 || st.Dependencies[0].To != bar.Info() {
+				t.Fatal(st.Dependencies)
+			}
+			if started := st.Since[mason.StateStarted]; started.IsZero() || started.Before(st.Since[mason.StateLoaded]) {
+				t.Fatal(st.Since)
+			}
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	// discover
+	bar := &module{name: "bar", version: "1.0.0"}
+	foo := &module{name: "foo", version: "1.0.0", deps: []mason.Info{bar.Info()}}
+	fooV2 := &module{name: "foo", version: "1.10.0", deps: []mason.Info{bar.Info()}}
+	// register
+	registry := mason.NewRegistry()
+	registry.Register(fooV2)
+	registry.Register(foo)
+	registry.Register(bar)
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("expected duplicate registration to panic")
+			}
+		}()
+		registry.Register(&module{name: "bar", version: "1.0.0"})
+	}()
+	var registered []string
+	for _, mod := range registry.Registered() {
+		registered = append(registered, mod.Info().String())
+	}
+	if actual := strings.Join(registered, ", "); actual != "bar-1.0.0, foo-1.0.0, foo-1.10.0" {
+		t.Fatal(actual)
+	}
+	if mod, ok := registry.Lookup("foo"); !ok || mod.Info() != fooV2.Info() {
+		t.Fatal(mod)
+	}
+	if _, ok := registry.Lookup("baz"); ok {
+		t.Fatal("expected baz to be unregistered")
+	}
+	// construct
+	scaffold := mason.New(&nopMortar{}, mason.RegistryOption(registry))
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+	// hook
+	if err := scaffold.LoadRegistered(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := mason.Len(scaffold); n != 3 {
+		t.Fatal(n)
+	}
+	// default, with a version unique to each run since the default Registry is process-wide
+	def := &module{name: "registry", version: fmt.Sprintf("1.0.%d", atomic.AddInt32(&defaults, 1))}
+	mason.Register(def)
+	if mod, ok := mason.Lookup("registry"); !ok || mod.Info() != def.Info() || len(mason.Registered()) == 0 {
+		t.Fatal(mason.Registered())
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	// discover
+	gzip := &module{name: "http.handlers.gzip", version: "1.0.0"}
+	static := &module{name: "http.handlers.static", version: "1.0.0"}
+	var loaded []mason.Info
+	server := namespaced{module: &module{name: "http.server", version: "1.0.0"}, namespace: "http.handlers", loaded: &loaded}
+	if ns := gzip.Info().Namespace(); ns != "http.handlers" || !gzip.Info().In("http") || gzip.Info().In("http.handler") {
+		t.Fatal(ns)
+	}
+	// register
+	registry := mason.NewRegistry()
+	registry.Register(gzip)
+	registry.Register(static)
+	registry.Register(server)
+	// construct
+	scaffold := mason.New(&nopMortar{}, mason.RegistryOption(registry))
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+	// hook
+	if err := scaffold.LoadNamespace(ctx, "http"); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(loaded) != "[http.handlers.gzip-1.0.0 http.handlers.static-1.0.0]" {
+		t.Fatal(loaded)
+	}
+	if handlers := scaffold.Namespace("http.handlers"); len(handlers) != 2 {
+		t.Fatal(handlers)
+	}
+	deps := scaffold.Snapshot().DependenciesOf(server.Info(), false)
+	if fmt.Sprint(deps) != "[http.handlers.gzip-1.0.0 http.handlers.static-1.0.0]" {
+		t.Fatal(deps)
+	}
+	// aggregator
+	loaded = nil
+	all := namespaced{module: &module{name: "http.handlers.all", version: "1.0.0"}, namespace: "http.handlers", loaded: &loaded}
+	if err := mason.New(&nopMortar{}).Load(ctx, all, gzip, static); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(loaded) != "[http.handlers.gzip-1.0.0 http.handlers.static-1.0.0]" {
+		t.Fatal(loaded)
+	}
+	// missing
+	loaded = nil
+	server.namespace = "http.middleware"
+	scaffold = mason.New(&nopMortar{})
+	if err := scaffold.Load(ctx, server); !errors.Is(err, mason.ErrMissingDependency) {
+		t.Fatal(err)
+	}
+	// invalid
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("expected invalid name to panic")
+			}
+		}()
+		registry.Register(&module{name: "http..gzip", version: "1.0.0"})
+	}()
+	if err := (mason.Info{Name: "github.com/acme/foo"}).Validate(); !errors.Is(err, mason.ErrInvalidName) {
+		t.Fatal(err)
+	}
+	if err := scaffold.Load(ctx, &module{name: "github.com/acme/foo", version: "1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := scaffold.LoadNamespace(ctx, "http."); !errors.Is(err, mason.ErrInvalidName) {
+		t.Fatal(err)
+	}
+}
+
+func TestContainer(t *testing.T) {
+	// discover
+	foo := provider{module: &module{name: "foo", version: "1.0.0"}, provides: []reflect.Type{mason.TypeOf[greeter]()}}
+	foo.provide = func(c *mason.Context) error {
+		return mason.Provide[greeter](c, english{})
+	}
+	var greeting string
+	bar := provider{module: &module{name: "bar", version: "1.0.0"}}
+	bar.provide = func(c *mason.Context) error {
+		g, err := mason.Resolve[greeter](c)
+		if err != nil {
+			return err
+		}
+		greeting = g.Greet()
+		return mason.Provide(c, 42, "answer")
+	}
+	// construct
+	container := mason.NewContainer()
+	scaffold := mason.New(container)
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	// hook
+	if err := load(ctx, cancel, scaffold, bar, foo); err != nil {
+		t.Fatal(err)
+	}
+	if greeting != "hello" {
+		t.Fatal(greeting)
+	}
+	var bindings []string
+	for _, b := range container.Bindings() {
+		bindings = append(bindings, fmt.Sprintf("%s=%v by %s", &b, b.Value, b.Module))
+	}
+	if actual := strings.Join(bindings, ", "); actual != "int 'answer'=42 by bar-1.0.0, mason_test.greeter={} by foo-1.0.0" {
+		t.Fatal(actual)
+	}
+	if deps := scaffold.Snapshot().DependenciesOf(bar.Info(), false); fmt.Sprint(deps) != "[foo-1.0.0]" {
+		t.Fatal(deps)
+	}
+	// duplicate
+	baz := provider{module: &module{name: "baz", version: "1.0.0"}}
+	baz.provide = func(c *mason.Context) error {
+		return mason.Provide[greeter](c, english{})
+	}
+	err := scaffold.Load(context.TODO(), baz)
+	if !errors.Is(err, mason.ErrDuplicateProvider) || !strings.Contains(err.Error(), "by baz-1.0.0, already provided by foo-1.0.0") {
+		t.Fatal(err)
+	}
+	baz.provide = func(c *mason.Context) error {
+		return mason.Provide[greeter](c, english{}, "baz")
+	}
+	if err = scaffold.Load(context.TODO(), baz); err != nil {
+		t.Fatal(err)
+	}
+	// unresolved
+	qux := provider{module: &module{name: "qux", version: "1.0.0"}}
+	qux.provide = func(c *mason.Context) error {
+		_, err := mason.Resolve[greeter](c, "qux")
+		return err
+	}
+	if err = scaffold.Load(context.TODO(), qux); !errors.Is(err, mason.ErrNotProvided) {
+		t.Fatal(err)
+	}
+	if err = mason.New(&nopMortar{}).Load(context.TODO(), qux); !errors.Is(err, mason.ErrNoContainer) {
+		t.Fatal(err)
+	}
+	// invalid
+	provided := len(container.Bindings())
+	for _, tc := range []*mason.Binding{
+		{Value: 1},
+		{Type: mason.TypeOf[int](), Value: "1", Name: "invalid"},
+		{Type: mason.TypeOf[int](), Name: "invalid"},
+	} {
+		if err = container.Hook(&mason.Binding{Type: mason.TypeOf[error](), Name: "invalid"}, tc); !errors.Is(err, mason.ErrInvalidBinding) {
+			t.Fatal(err)
+		}
+		if len(container.Bindings()) != provided {
+			t.Fatal(container.Bindings())
+		}
+	}
+	if err = container.Hook(&mason.Binding{Type: mason.TypeOf[greeter](), Name: "nil"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRouter(t *testing.T) {
+	errNegative := errors.New("negative")
+	var routed []string
+	// construct
+	router := mason.NewRouter()
+	router.Handle(func(s fmt.Stringer) error {
+		routed = append(routed, "stringer "+s.String())
+		return nil
+	})
+	router.Handle(func(d time.Duration) error {
+		routed = append(routed, "duration "+d.String())
+		return nil
+	})
+	router.Handle(func(n int) error {
+		if n < 0 {
+			return errNegative
+		}
+		routed = append(routed, fmt.Sprint("int ", n))
+		return nil
+	})
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("expected invalid handler to panic")
+			}
+		}()
+		router.Handle(func(n int) {})
+	}()
+	// discover
+	foo := &module{name: "foo", version: "1.0.0", services: []mason.Stone{1, time.Second, mason.Info{Name: "bar", Version: "1.0.0"}}}
+	scaffold := mason.New(router)
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	// hook
+	if err := load(ctx, cancel, scaffold, foo); err != nil {
+		t.Fatal(err)
+	}
+	if actual := strings.Join(routed, ", "); actual != "int 1, duration 1s, stringer bar-1.0.0" {
+		t.Fatal(actual)
+	}
+	// unhandled
+	routed = nil
+	baz := &module{name: "baz", version: "1.0.0", services: []mason.Stone{2, "baz"}}
+	err := scaffold.Load(context.TODO(), baz)
+	if !errors.Is(err, mason.ErrUnhandledStone) || !strings.Contains(err.Error(), "unhandled stone string from baz-1.0.0") {
+		t.Fatal(err)
+	}
+	if len(routed) != 0 {
+		t.Fatal(routed)
+	}
+	qux := &module{name: "qux", version: "1.0.0", services: []mason.Stone{-1}}
+	if err = scaffold.Load(context.TODO(), qux); !errors.Is(err, errNegative) {
+		t.Fatal(err)
+	}
+}
+
+func TestChain(t *testing.T) {
+	errForbidden := errors.New("forbidden")
+	var logged []string
+	logging := func(next mason.HookFunc) mason.HookFunc {
+		return func(info mason.Info, stone ...mason.Stone) error {
+			logged = append(logged, fmt.Sprintf("%s %v", info, stone))
+			return next(info, stone...)
+		}
+	}
+	validating := func(next mason.HookFunc) mason.HookFunc {
+		return func(info mason.Info, stone ...mason.Stone) error {
+			for _, s := range stone {
+				if s == "forbidden" {
+					return errForbidden
+				}
+			}
+			return next(info, stone...)
+		}
+	}
+	// construct
+	mort := &nopMortar{}
+	scaffold := mason.New(mason.Chain(logging, validating)(mort))
+	// hook
+	foo := &module{name: "foo", version: "1.0.0", services: []mason.Stone{"foo"}}
+	if err := scaffold.Load(context.TODO(), foo); err != nil {
+		t.Fatal(err)
+	}
+	bar := &module{name: "bar", version: "1.0.0", services: []mason.Stone{"forbidden"}}
+	if err := scaffold.Load(context.TODO(), bar); !errors.Is(err, errForbidden) {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(logged) != "[foo-1.0.0 [foo] bar-1.0.0 [forbidden]]" || fmt.Sprint(mort.list()) != "[foo]" {
+		t.Fatal(logged, mort.list())
+	}
+	// unhook
+	if err := scaffold.Unload(context.TODO(), foo.Info(), false); err != nil || len(mort.list()) != 0 {
+		t.Fatal(err, mort.list())
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	// construct
+	a, b := &nopMortar{}, &nopMortar{}
+	scaffold := mason.New(mason.FanOut(a, b, mason.NewRouter()))
+	// hook
+	foo := &module{name: "foo", version: "1.0.0", services: []mason.Stone{"foo"}}
+	if err := scaffold.Load(context.TODO(), foo); !errors.Is(err, mason.ErrUnhandledStone) {
+		t.Fatal(err)
+	}
+	if len(a.list()) != 0 || len(b.list()) != 0 {
+		t.Fatal(a.list(), b.list())
+	}
+	scaffold = mason.New(mason.FanOut(a, b))
+	if err := scaffold.Load(context.TODO(), foo); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(a.list(), b.list()) != "[foo] [foo]" {
+		t.Fatal(a.list(), b.list())
+	}
+}
+
+func TestFirstAccepting(t *testing.T) {
+	var routed []int
+	// construct
+	router := mason.NewRouter()
+	router.Handle(func(n int) error {
+		routed = append(routed, n)
+		return nil
+	})
+	container := mason.NewContainer()
+	scaffold := mason.New(mason.FirstAccepting(container, router))
+	// hook
+	var resolved int
+	foo := provider{module: &module{name: "foo", version: "1.0.0", services: []mason.Stone{1, 2}}}
+	foo.provide = func(c *mason.Context) error {
+		if err := mason.Provide(c, 3); err != nil {
+			return err
+		}
+		var err error
+		resolved, err = mason.Resolve[int](c)
+		return err
+	}
+	if err := scaffold.Load(context.TODO(), foo); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(routed) != "[1 2]" || resolved != 3 || len(container.Bindings()) != 1 {
+		t.Fatal(routed, resolved, container.Bindings())
+	}
+	bar := &module{name: "bar", version: "1.0.0", services: []mason.Stone{"bar"}}
+	err := scaffold.Load(context.TODO(), bar)
+	if !errors.Is(err, mason.ErrUnhandledStone) || !strings.Contains(err.Error(), "string from bar-1.0.0") {
+		t.Fatal(err)
+	}
+	// rollback
+	baz := &module{name: "baz", version: "1.0.0", services: []mason.Stone{&mason.Binding{Type: mason.TypeOf[string](), Value: "baz"}, "baz"}}
+	if err = scaffold.Load(context.TODO(), baz); !errors.Is(err, mason.ErrUnhandledStone) || len(container.Bindings()) != 1 {
+		t.Fatal(err, container.Bindings())
+	}
+	// unhook
+	if err = scaffold.Unload(context.TODO(), foo.Info(), false); err != nil || len(container.Bindings()) != 0 {
+		t.Fatal(err, container.Bindings())
+	}
+	// incomparable
+	type incomparable struct{ v any }
+	mort := mason.FirstAccepting(&nopMortar{})
+	if err = mort.Hook(incomparable{v: []int{1}}); err != nil {
+		t.Fatal(err)
+	}
+	if err = mort.(mason.Unhooker).Unhook(incomparable{v: []int{2}}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -22,6 +22,7 @@
 package mason
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"time"
@@ -32,6 +33,10 @@ var (
 	ErrSelfReferentialDependency error = errors.New("self-referential module dependency")
 	ErrCircularDependency        error = errors.New("circular module dependency")
 	ErrMissingDependency         error = errors.New("missing module dependency")
+	ErrLoadedDependents          error = errors.New("module has loaded dependents")
+	ErrProvisionTimeout          error = errors.New("module provision timed out")
+	ErrInvalidTransition         error = errors.New("invalid module state transition")
+	ErrInvalidName               error = errors.New("invalid module name")
 )
 
 type (
@@ -47,26 +52,91 @@ type (
 		// Provision initializes.
 		Provision(c *Context) error
 	}
+	// Dependent is an optional Module interface for declaring dependencies statically, so that they can be planned
+	// before any Module is provisioned. Declared dependencies are loaded before Module.Provision.
+	Dependent interface {
+		// Dependencies lists Module dependencies by Info.
+		Dependencies() []Info
+	}
+	// Timeouter is an optional Module interface for bounding how long Module.Provision, including dependencies,
+	// may take. It is overridden by TimeoutOption.
+	Timeouter interface {
+		// Timeout returns the provisioning deadline budget, or zero for none.
+		Timeout() time.Duration
+	}
+	// Starter is an optional Module interface for starting after all Module(s) have been provisioned.
+	Starter interface {
+		// Start starts the Module.
+		Start(ctx context.Context) error
+	}
+	// Stopper is an optional Module interface for releasing resources on shutdown, similar to
+	// https://caddyserver.com/docs/extending-caddy#cleanup.
+	Stopper interface {
+		// Stop stops the Module.
+		Stop(ctx context.Context) error
+	}
 	// moduleWrapper wraps Module to track status.
 	moduleWrapper struct {
 		Module
-		loaded  bool
-		runtime time.Duration
-		depsMu  sync.RWMutex
-		deps    []Info
+		state    State
+		since    map[State]time.Time
+		runtime  time.Duration
+		stones   []Stone
+		err      error
+		depsMu   sync.RWMutex
+		deps     []Info
+		optional map[Info]bool
 	}
 	// Dependency is a Module dependency relationship.
 	Dependency struct {
 		From Info
 		To   Info
+		// Optional is set if From loaded To with Context.LoadOptional.
+		Optional bool
 	}
 )
 
-// dependsOn safely appends Module(s) as dependencies.
-func (w *moduleWrapper) dependsOn(info ...Info) {
+// dependsOn safely appends Module(s) as dependencies. A dependency is only optional if it is never required.
+func (w *moduleWrapper) dependsOn(optional bool, info ...Info) {
+	w.depsMu.Lock()
+	defer w.depsMu.Unlock()
+	for _, i := range info {
+		if !w.hasDep(i) {
+			w.deps = append(w.deps, i)
+			if optional {
+				if w.optional == nil {
+					w.optional = make(map[Info]bool)
+				}
+				w.optional[i] = true
+			}
+		} else if !optional {
+			delete(w.optional, i)
+		}
+	}
+}
+
+// hasDep checks whether a Module is already a dependency. The caller must hold depsMu.
+func (w *moduleWrapper) hasDep(info Info) bool {
+	for _, dep := range w.deps {
+		if dep == info {
+			return true
+		}
+	}
+	return false
+}
+
+// forget safely removes a Module from the dependencies, such as when it is unloaded.
+func (w *moduleWrapper) forget(info Info) {
 	w.depsMu.Lock()
 	defer w.depsMu.Unlock()
-	w.deps = append(w.deps, info...)
+	deps := w.deps[:0]
+	for _, dep := range w.deps {
+		if dep != info {
+			deps = append(deps, dep)
+		}
+	}
+	w.deps = deps
+	delete(w.optional, info)
 }
 
 // listDeps safely lists Module dependencies.
@@ -74,7 +144,7 @@ func (w *moduleWrapper) listDeps() (deps []Dependency) {
 	w.depsMu.RLock()
 	defer w.depsMu.RUnlock()
 	for _, dep := range w.deps {
-		deps = append(deps, Dependency{From: w.Info(), To: dep})
+		deps = append(deps, Dependency{From: w.Info(), To: dep, Optional: w.optional[dep]})
 	}
 	return
 }
@@ -86,5 +156,8 @@ func (i Info) String() string {
 
 // String implements fmt.Stringer.
 func (d Dependency) String() string {
+	if d.Optional {
+		return d.To.String() + " <= " + d.From.String() + " (optional)"
+	}
 	return d.To.String() + " <= " + d.From.String()
 }
@@ -0,0 +1,128 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+package mason
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Namespace returns the namespace of a Module, which is its name up to the last dot, such as http.handlers for
+// http.handlers.gzip.
+func (i Info) Namespace() string {
+	if n := strings.LastIndexByte(i.Name, '.'); n >= 0 {
+		return i.Name[:n]
+	}
+	return ""
+}
+
+// In checks whether a Module is within a namespace, including nested namespaces. Every Module is within the empty
+// namespace.
+func (i Info) In(namespace string) bool {
+	return namespace == "" || strings.HasPrefix(i.Name, namespace+".")
+}
+
+// Validate checks that a Module name is a dotted ID of non-empty labels, such as http.handlers.gzip, where each label
+// consists of letters, digits, underscores and hyphens. It is enforced by Registry.Register, whereas Scaffold.Load
+// accepts any name.
+func (i Info) Validate() error {
+	if err := validateNamespace(i.Name); err != nil || i.Name == "" {
+		return fmt.Errorf("%w '%s'", ErrInvalidName, i.Name)
+	}
+	return nil
+}
+
+// validateNamespace checks that a namespace is empty or a dotted ID.
+func validateNamespace(namespace string) error {
+	if namespace == "" {
+		return nil
+	}
+	for _, label := range strings.Split(namespace, ".") {
+		if label == "" {
+			return fmt.Errorf("%w '%s'", ErrInvalidName, namespace)
+		}
+		for _, r := range label {
+			if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '_' || r == '-') {
+				return fmt.Errorf("%w '%s'", ErrInvalidName, namespace)
+			}
+		}
+	}
+	return nil
+}
+
+// Namespace lists registered Module(s) within a namespace, ordered by Info.
+func (s *Scaffold) Namespace(namespace string) (info []Info) {
+	s.modulesMu.RLock()
+	for _, w := range s.modules {
+		if i := w.Info(); i.In(namespace) {
+			info = append(info, i)
+		}
+	}
+	s.modulesMu.RUnlock()
+	sortInfo(info)
+	return
+}
+
+// LoadNamespace loads every Module within a namespace from the Registry of Scaffold(ing).
+func (s *Scaffold) LoadNamespace(ctx context.Context, namespace string) error {
+	if err := validateNamespace(namespace); err != nil {
+		return fmt.Errorf("scaffold failed to load, %w", err)
+	}
+	return s.Load(ctx, s.registry.Namespace(namespace)...)
+}
+
+// Namespace lists registered Module(s) within a namespace, ordered by name and version.
+func (r *Registry) Namespace(namespace string) (mods []Module) {
+	for _, mod := range r.Registered() {
+		if mod.Info().In(namespace) {
+			mods = append(mods, mod)
+		}
+	}
+	return
+}
+
+// LoadNamespace loads every registered Module within a namespace as a dependency, such that the dependency is
+// satisfied by any Module in the namespace. Module(s) being provisioned, such as an aggregator within the namespace
+// it loads, are excluded. ErrMissingDependency is returned if no other Module is in the namespace.
+func (c *Context) LoadNamespace(namespace string) (loaded []Info, err error) {
+	if err = validateNamespace(namespace); err == nil {
+		for _, info := range c.scaffold.Namespace(namespace) {
+			if !c.stack.Has(info) {
+				loaded = append(loaded, info)
+			}
+		}
+		if len(loaded) == 0 {
+			err = fmt.Errorf("%w in namespace '%s'", ErrMissingDependency, namespace)
+		}
+	}
+	if err != nil {
+		if c.stack.Size() > 0 {
+			c.stack.Log(err)
+		}
+		return nil, err
+	}
+	if err = c.Load(loaded...); err != nil {
+		return nil, err
+	}
+	return
+}
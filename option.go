@@ -21,6 +21,11 @@
 
 package mason
 
+import (
+	"log/slog"
+	"time"
+)
+
 var (
 	// DefaultSkipper skips no Module(s).
 	DefaultSkipper Skipper = func(_ Info) bool {
@@ -43,9 +48,86 @@ func SkipOption(skip Skipper) Option {
 	}
 }
 
-// OnLoad enables an Event subscription for observation.
-func OnLoad(ch chan<- Event) Option {
+// OnLoad enables an Event subscription for observation. Event(s) are sent synchronously, so the channel must be
+// drained or buffered; see Scaffold.Subscribe for non-blocking alternatives.
+func OnLoad(ch chan<- Event, opt ...SubscriberOption) Option {
+	return func(s *Scaffold) {
+		s.Subscribe(ch, opt...)
+	}
+}
+
+// LoggerOption scopes Context.Logger for Module(s) and logs every Event with LogEvent.
+func LoggerOption(logger *slog.Logger) Option {
+	return func(s *Scaffold) {
+		s.logger = logger
+		s.SubscribeFunc(LogEvent(logger))
+	}
+}
+
+// RegistryOption overrides the Registry used by Scaffold.LoadRegistered, such as to isolate tests from the default
+// Registry.
+func RegistryOption(r *Registry) Option {
+	return func(s *Scaffold) {
+		s.registry = r
+	}
+}
+
+// Parallel provisions up to n Module(s) concurrently on Scaffold.Load. Module(s) loaded as a dependency by more
+// than one Module are only provisioned once.
+func Parallel(n int) Option {
+	return func(s *Scaffold) {
+		if n > 0 {
+			s.workers = n
+		}
+	}
+}
+
+// Transactional records every Stone hooked by each Module and unhooks them in reverse order if the Module, or
+// Scaffold.Load as a whole, fails. Module(s) loaded by a failed Scaffold.Load are reverted to unloaded, leaving
+// Mortar as it was. Mortar must implement Unhooker, otherwise Scaffold.Load fails with ErrUnhookUnsupported, and
+// Mortar.Hook must be all-or-nothing, since Stone are only recorded once hooked.
+func Transactional() Option {
+	return func(s *Scaffold) {
+		s.transactional = true
+	}
+}
+
+// RecoverOption decides whether Module.Provision panics are recovered as a PanicError, which is the default, or
+// re-raised as a *PanicError by Scaffold.Load.
+func RecoverOption(enabled bool) Option {
+	return func(s *Scaffold) {
+		s.recover = enabled
+	}
+}
+
+// ContinueOnError isolates Module failures on Scaffold.Load. A failed Module, and every Module that transitively
+// depends on it, is marked as failed while unrelated Module(s) still load. This includes Module(s) with missing or
+// circular static dependencies, which would otherwise fail planning. Scaffold.Load then returns every failure
+// joined, each as a LoadError.
+func ContinueOnError() Option {
+	return func(s *Scaffold) {
+		s.continueOnError = true
+	}
+}
+
+// TimeoutOption bounds how long Module.Provision, including dependencies, may take for a Module by Info. Each
+// Module is provisioned with a child Context, which Module.Provision should honor, and exceeding the deadline fails
+// with ErrProvisionTimeout. Zero disables the deadline, even if the Module implements Timeouter.
+func TimeoutOption(info Info, d time.Duration) Option {
+	return func(s *Scaffold) {
+		s.timeouts[info.String()] = d
+	}
+}
+
+// RetryOption retries Module.Provision according to a RetryPolicy for Module(s) by Info, or for all Module(s) if
+// none are given.
+func RetryOption(policy RetryPolicy, info ...Info) Option {
 	return func(s *Scaffold) {
-		s.ch = ch
+		if len(info) == 0 {
+			s.retry = policy
+		}
+		for _, i := range info {
+			s.retries[i.String()] = policy
+		}
 	}
 }
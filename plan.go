@@ -0,0 +1,228 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+package mason
+
+import (
+	"sort"
+	"strings"
+)
+
+type (
+	// PlanError reports every missing and circular static Module dependency found by Scaffold.Plan.
+	PlanError struct {
+		Missing []Dependency
+		Cycles  [][]Info
+	}
+)
+
+// Error implements error.
+func (e *PlanError) Error() string {
+	var problems []string
+	for _, dep := range e.Missing {
+		problems = append(problems, ErrMissingDependency.Error()+" "+dep.String())
+	}
+	for _, cycle := range e.Cycles {
+		err := ErrCircularDependency
+		if len(cycle) == 2 {
+			err = ErrSelfReferentialDependency
+		}
+		problems = append(problems, err.Error()+" "+chain(cycle))
+	}
+	return "scaffold failed to plan, " + strings.Join(problems, "; ")
+}
+
+// Is allows PlanError to satisfy errors.Is with the sentinel errors of its problems.
+func (e *PlanError) Is(target error) bool {
+	switch target {
+	case ErrMissingDependency:
+		return len(e.Missing) > 0
+	case ErrCircularDependency:
+		for _, cycle := range e.Cycles {
+			if len(cycle) > 2 {
+				return true
+			}
+		}
+	case ErrSelfReferentialDependency:
+		for _, cycle := range e.Cycles {
+			if len(cycle) == 2 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// chain formats a dependency chain.
+func chain(info []Info) string {
+	refs := make([]string, 0, len(info))
+	for _, i := range info {
+		refs = append(refs, i.String())
+	}
+	return strings.Join(refs, " -> ")
+}
+
+// Plan returns the order in which registered Module(s), along with any additional Module(s), would be loaded
+// according to their static Dependent declarations, without provisioning anything. Every missing and circular
+// dependency is reported at once by a PlanError.
+func (s *Scaffold) Plan(mod ...Module) ([]Info, error) {
+	mods, loaded := s.planning()
+	roots := make([]Info, 0, len(mods)+len(mod))
+	for _, m := range mods {
+		roots = append(roots, m.Info())
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		return roots[i].String() < roots[j].String()
+	})
+	for _, m := range mod {
+		info := m.Info()
+		if s.skip(info) {
+			continue
+		}
+		if _, ok := mods[info.String()]; !ok {
+			roots = append(roots, info)
+		}
+		if !loaded[info.String()] {
+			mods[info.String()] = m
+		}
+	}
+	return plan(mods, roots)
+}
+
+// planning copies registered Module(s) for planning, along with whether they have been loaded.
+func (s *Scaffold) planning() (mods map[string]Module, loaded map[string]bool) {
+	s.modulesMu.RLock()
+	defer s.modulesMu.RUnlock()
+	mods = make(map[string]Module, len(s.modules))
+	loaded = make(map[string]bool, len(s.modules))
+	for ref, w := range s.modules {
+		mods[ref] = w.Module
+		loaded[ref] = w.state.Loaded()
+	}
+	return
+}
+
+// isolate marks the Module(s) with missing or circular static dependencies, along with every Module that
+// statically depends on them, as failed and removes them from planning. The failed Module(s) are returned.
+func (s *Scaffold) isolate(mods map[string]Module, e *PlanError) (failed []Info) {
+	causes := make(map[string]error)
+	for _, dep := range e.Missing {
+		causes[dep.From.String()] = &LoadError{
+			Info:       dep.From,
+			Dependency: dep.To,
+			Chain:      []Info{dep.From, dep.To},
+			Err:        ErrMissingDependency,
+		}
+	}
+	for _, cycle := range e.Cycles {
+		err := ErrCircularDependency
+		if len(cycle) == 2 {
+			err = ErrSelfReferentialDependency
+		}
+		for i, info := range cycle[:len(cycle)-1] {
+			if _, ok := causes[info.String()]; !ok {
+				causes[info.String()] = &LoadError{Info: info, Dependency: cycle[i+1], Chain: cycle, Err: err}
+			}
+		}
+	}
+	// fail dependents with the cause, like Context.Load
+	for changed := true; changed; {
+		changed = false
+		for ref, m := range mods {
+			dependent, ok := m.(Dependent)
+			if _, failed := causes[ref]; failed || !ok {
+				continue
+			}
+			for _, dep := range dependent.Dependencies() {
+				if cause, ok := causes[dep.String()]; ok {
+					causes[ref], changed = cause, true
+					break
+				}
+			}
+		}
+	}
+	s.modulesMu.Lock()
+	for ref, err := range causes {
+		delete(mods, ref)
+		if w, ok := s.modules[ref]; ok && w.state == StateRegistered {
+			w.err = err
+			_ = w.transition(StateFailed)
+			failed = append(failed, w.Info())
+		}
+	}
+	s.modulesMu.Unlock()
+	sortInfo(failed)
+	for _, info := range failed {
+		s.publish(Event{Info: info, Phase: PhaseFailed, err: causes[info.String()]})
+	}
+	return
+}
+
+// plan topologically sorts the Module(s) reachable from roots by their static dependencies.
+func plan(mods map[string]Module, roots []Info) (order []Info, err error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	var (
+		e     PlanError
+		state = make(map[string]int, len(mods))
+		path  []Info
+		visit func(info Info)
+	)
+	visit = func(info Info) {
+		ref := info.String()
+		switch state[ref] {
+		case visiting:
+			for i := range path {
+				if path[i] == info {
+					cycle := append(append([]Info(nil), path[i:]...), info)
+					e.Cycles = append(e.Cycles, cycle)
+					break
+				}
+			}
+			return
+		case visited:
+			return
+		}
+		state[ref] = visiting
+		path = append(path, info)
+		if dependent, ok := mods[ref].(Dependent); ok {
+			for _, dep := range dependent.Dependencies() {
+				if _, ok := mods[dep.String()]; !ok {
+					e.Missing = append(e.Missing, Dependency{From: info, To: dep})
+					continue
+				}
+				visit(dep)
+			}
+		}
+		path = path[:len(path)-1]
+		state[ref] = visited
+		order = append(order, info)
+	}
+	for _, root := range roots {
+		visit(root)
+	}
+	if len(e.Missing) > 0 || len(e.Cycles) > 0 {
+		return nil, &e
+	}
+	return
+}
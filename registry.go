@@ -0,0 +1,120 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+package mason
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+var (
+	// registry is the default Registry.
+	registry = NewRegistry()
+)
+
+type (
+	// Registry is a set of Module(s) registered for discovery, such as from init functions of anonymously imported
+	// packages, which mimics https://pkg.go.dev/database/sql#Register.
+	Registry struct {
+		mu      sync.RWMutex
+		modules map[string]Module
+	}
+)
+
+// NewRegistry creates an isolated Registry, such as for tests.
+func NewRegistry() *Registry {
+	return &Registry{modules: make(map[string]Module)}
+}
+
+// Register makes a Module available by its Info. If Register is called twice with the same Info, or if the Module
+// is nil or has an invalid name, it panics.
+func (r *Registry) Register(mod Module) {
+	if mod == nil {
+		panic("mason: Register module is nil")
+	}
+	info := mod.Info()
+	if err := info.Validate(); err != nil {
+		panic("mason: Register " + err.Error())
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, dup := r.modules[info.String()]; dup {
+		panic("mason: Register called twice for module " + info.String())
+	}
+	r.modules[info.String()] = mod
+}
+
+// Registered lists registered Module(s), ordered by name and version.
+func (r *Registry) Registered() []Module {
+	r.mu.RLock()
+	mods := make([]Module, 0, len(r.modules))
+	for _, mod := range r.modules {
+		mods = append(mods, mod)
+	}
+	r.mu.RUnlock()
+	sort.Slice(mods, func(i, j int) bool {
+		a, b := mods[i].Info(), mods[j].Info()
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return lessVersion(a.Version, b.Version)
+	})
+	return mods
+}
+
+// Lookup returns the highest registered version of a Module by name.
+func (r *Registry) Lookup(name string) (mod Module, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, m := range r.modules {
+		if m.Info().Name != name {
+			continue
+		}
+		if !ok || lessVersion(mod.Info().Version, m.Info().Version) {
+			mod, ok = m, true
+		}
+	}
+	return
+}
+
+// Register makes a Module available in the default Registry. If Register is called twice with the same Info, or if
+// the Module is nil or has an invalid name, it panics.
+func Register(mod Module) {
+	registry.Register(mod)
+}
+
+// Registered lists Module(s) in the default Registry, ordered by name and version.
+func Registered() []Module {
+	return registry.Registered()
+}
+
+// Lookup returns the highest version of a Module by name in the default Registry.
+func Lookup(name string) (Module, bool) {
+	return registry.Lookup(name)
+}
+
+// LoadRegistered loads every Module in the Registry of Scaffold(ing), which is the default Registry unless
+// overridden by RegistryOption.
+func (s *Scaffold) LoadRegistered(ctx context.Context) error {
+	return s.Load(ctx, s.registry.Registered()...)
+}
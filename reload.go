@@ -0,0 +1,133 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+package mason
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Unload stops a Module, unhooks its Stone if Mortar implements Unhooker, and removes it from Scaffold(ing). If
+// cascade is set, every Module that transitively depends on it is unloaded first, in reverse dependency order.
+// Otherwise, ErrLoadedDependents is returned if any loaded Module depends on it.
+func (s *Scaffold) Unload(ctx context.Context, info Info, cascade bool) error {
+	mods, err := s.unloading(info, cascade)
+	if err != nil {
+		return fmt.Errorf("scaffold failed to unload, %w", err)
+	}
+	if errs := s.unload(ctx, mods); len(errs) > 0 {
+		return fmt.Errorf("scaffold failed to unload, %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Reload swaps in a new implementation of a registered Module, by Info, and re-provisions it along with every
+// Module that transitively depends on it. Module(s) that were started before Reload are started again.
+func (s *Scaffold) Reload(ctx context.Context, mod Module) error {
+	mods, err := s.unloading(mod.Info(), true)
+	if err != nil {
+		return fmt.Errorf("scaffold failed to reload, %w", err)
+	}
+	started := make(map[string]bool, len(mods))
+	for _, w := range mods {
+		started[w.Info().String()] = s.started(w)
+	}
+	if errs := s.unload(ctx, mods); len(errs) > 0 {
+		return fmt.Errorf("scaffold failed to reload, %w", errors.Join(errs...))
+	}
+	// reload in dependency order
+	var reloading []Module
+	for i := len(mods) - 1; i >= 0; i-- {
+		w := mods[i]
+		if w.Info() == mod.Info() {
+			reloading = append(reloading, mod)
+		} else {
+			reloading = append(reloading, w.Module)
+		}
+	}
+	if err = s.Load(ctx, reloading...); err != nil {
+		return fmt.Errorf("scaffold failed to reload, %w", err)
+	}
+	var restart []*moduleWrapper
+	for _, m := range reloading {
+		if w, ok := s.get(m.Info()); ok && started[m.Info().String()] {
+			restart = append(restart, w)
+		}
+	}
+	if err = s.start(ctx, restart); err != nil {
+		return fmt.Errorf("scaffold failed to reload, %w", err)
+	}
+	return nil
+}
+
+// unloading lists the Module(s) to unload, in reverse dependency order.
+func (s *Scaffold) unloading(info Info, cascade bool) (mods []*moduleWrapper, err error) {
+	if _, ok := s.get(info); !ok {
+		return nil, fmt.Errorf("%w %s", ErrInvalidModule, info)
+	}
+	snap := s.Snapshot()
+	unload := map[string]bool{info.String(): true}
+	for _, dependent := range snap.DependentsOf(info, true) {
+		if w, ok := s.get(dependent); ok && s.isLoaded(w) {
+			if !cascade {
+				return nil, fmt.Errorf("%w %s", ErrLoadedDependents, info)
+			}
+			unload[dependent.String()] = true
+		}
+	}
+	order := snap.TopoOrder()
+	for i := len(order) - 1; i >= 0; i-- {
+		if w, ok := s.get(order[i]); ok && unload[order[i].String()] {
+			mods = append(mods, w)
+		}
+	}
+	return
+}
+
+// unload stops, unhooks, and removes Module(s) in order.
+func (s *Scaffold) unload(ctx context.Context, mods []*moduleWrapper) (errs []error) {
+	for _, w := range mods {
+		info, start := w.Info(), time.Now()
+		s.modulesMu.RLock()
+		loaded, stones := w.state.Loaded(), w.stones
+		s.modulesMu.RUnlock()
+		if loaded {
+			errs = append(errs, s.stop(ctx, []*moduleWrapper{w})...)
+		}
+		err := s.unhook(stones...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s failed to unhook, %w", info, err))
+		}
+		s.modulesMu.Lock()
+		_ = w.transition(StateUnloaded)
+		delete(s.modules, info.String())
+		s.unloaded[info.String()] = w
+		for _, dependent := range s.modules {
+			dependent.forget(info)
+		}
+		s.modulesMu.Unlock()
+		s.publish(Event{Info: info, Phase: PhaseUnloaded, Time: start, Duration: time.Since(start), err: err})
+	}
+	return
+}
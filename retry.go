@@ -0,0 +1,89 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+package mason
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+type (
+	// RetryPolicy retries a failing Module.Provision with exponential backoff and jitter. Failures of dependencies
+	// and of the dependency graph itself, such as ErrCircularDependency, are never retried. Stone hooked by a failed
+	// attempt are only unhooked if Transactional.
+	RetryPolicy struct {
+		// MaxAttempts is the maximum number of attempts, including the first.
+		MaxAttempts int
+		// Backoff is the delay before the second attempt.
+		Backoff time.Duration
+		// MaxBackoff caps the delay between attempts, if set.
+		MaxBackoff time.Duration
+		// Multiplier grows the delay after each attempt, defaulting to 2.
+		Multiplier float64
+		// Jitter randomizes each delay by up to a fraction of itself, between 0 and 1.
+		Jitter float64
+		// Retryable classifies errors as retryable, defaulting to all.
+		Retryable func(error) bool
+	}
+)
+
+// retryPolicy returns the RetryPolicy for a Module.
+func (s *Scaffold) retryPolicy(info Info) RetryPolicy {
+	if policy, ok := s.retries[info.String()]; ok {
+		return policy
+	}
+	return s.retry
+}
+
+// retryable decides whether a Module should retry after an error.
+func (p RetryPolicy) retryable(info Info, err error) bool {
+	var e *LoadError
+	if errors.As(err, &e) && e.Info != info {
+		return false
+	}
+	for _, target := range []error{
+		ErrInvalidModule, ErrMissingDependency, ErrCircularDependency, ErrSelfReferentialDependency,
+	} {
+		if errors.Is(err, target) {
+			return false
+		}
+	}
+	return p.Retryable == nil || p.Retryable(err)
+}
+
+// backoff returns the delay after an attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	d := float64(p.Backoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d *= 1 + p.Jitter*(2*rand.Float64()-1) //nolint:gosec // jitter does not need to be secure
+	}
+	return time.Duration(d)
+}
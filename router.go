@@ -0,0 +1,127 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+package mason
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	// interface guard.
+	_ Mortar       = (*Router)(nil)
+	_ ModuleHooker = (*Router)(nil)
+	// errorType is the reflect.Type of error.
+	errorType = TypeOf[error]()
+)
+
+type (
+	// Router is a Mortar that dispatches each Stone to the handler registered for its type, replacing type switches
+	// over Stone.
+	Router struct {
+		mu         sync.RWMutex
+		exact      map[reflect.Type]reflect.Value
+		interfaces []route
+	}
+	// route is a handler of an interface type.
+	route struct {
+		t  reflect.Type
+		fn reflect.Value
+	}
+)
+
+// NewRouter creates a Router without handlers.
+func NewRouter() *Router {
+	return &Router{exact: make(map[reflect.Type]reflect.Value)}
+}
+
+// Handle registers a handler of the form func(T) error for Stone of type T. If T is an interface, the handler
+// receives every Stone implementing it that has no handler for its exact type, with interfaces matched in
+// registration order. If the handler is of the wrong form or T already has a handler, Handle panics.
+func (r *Router) Handle(handler any) {
+	fn := reflect.ValueOf(handler)
+	if !fn.IsValid() || fn.Kind() != reflect.Func || fn.Type().NumIn() != 1 || fn.Type().NumOut() != 1 ||
+		fn.Type().Out(0) != errorType {
+		panic(fmt.Sprintf("mason: Router.Handle expects func(T) error, got %T", handler))
+	}
+	in := fn.Type().In(0)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if in.Kind() != reflect.Interface {
+		if _, dup := r.exact[in]; dup {
+			panic("mason: Router.Handle called twice for " + in.String())
+		}
+		r.exact[in] = fn
+		return
+	}
+	for _, rt := range r.interfaces {
+		if rt.t == in {
+			panic("mason: Router.Handle called twice for " + in.String())
+		}
+	}
+	r.interfaces = append(r.interfaces, route{t: in, fn: fn})
+}
+
+// Hook implements Mortar for Stone that were not provided by a Module.
+func (r *Router) Hook(stone ...Stone) error {
+	return r.HookModule(Info{}, stone...)
+}
+
+// HookModule implements ModuleHooker. Every Stone is routed before any handler is called, so that Stone without a
+// matching handler fail with ErrUnhandledStone without side effects. Router does not implement Unhooker, so the side
+// effects of handlers that ran before a failing handler are not rolled back.
+func (r *Router) HookModule(info Info, stone ...Stone) error {
+	fns := make([]reflect.Value, len(stone))
+	for i, s := range stone {
+		fn, ok := r.route(s)
+		if !ok {
+			return fmt.Errorf("%w %T from %s", ErrUnhandledStone, s, info)
+		}
+		fns[i] = fn
+	}
+	for i, s := range stone {
+		if out := fns[i].Call([]reflect.Value{reflect.ValueOf(s)}); !out[0].IsNil() {
+			return fmt.Errorf("router failed to hook %T from %s, %w", s, info, out[0].Interface().(error))
+		}
+	}
+	return nil
+}
+
+// route finds the handler for a Stone.
+func (r *Router) route(stone Stone) (reflect.Value, bool) {
+	if stone == nil {
+		return reflect.Value{}, false
+	}
+	t := reflect.TypeOf(stone)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if fn, ok := r.exact[t]; ok {
+		return fn, true
+	}
+	for _, rt := range r.interfaces {
+		if t.Implements(rt.t) {
+			return rt.fn, true
+		}
+	}
+	return reflect.Value{}, false
+}
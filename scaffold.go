@@ -23,38 +23,55 @@ package mason
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/pedregon/mason/v2/internal/stack"
+	"log/slog"
 	"sync"
 	"time"
 )
 
 type (
-	// Event is a Context event.
-	Event struct {
-		Info
-		err error
-	}
 	// Scaffold is a constructor for Module(s).
 	Scaffold struct {
-		mort      Mortar
-		modulesMu sync.RWMutex
-		modules   map[string]*moduleWrapper
-		ch        chan<- Event
-		skip      Skipper
+		mort            Mortar
+		modulesMu       sync.RWMutex
+		modules         map[string]*moduleWrapper
+		subsMu          sync.RWMutex
+		subs            []*Subscription
+		unloaded        map[string]*moduleWrapper
+		registry        *Registry
+		logger          *slog.Logger
+		skip            Skipper
+		skipped         map[string]Info
+		workers         int
+		transactional   bool
+		recover         bool
+		timeouts        map[string]time.Duration
+		retry           RetryPolicy
+		retries         map[string]RetryPolicy
+		continueOnError bool
+		flightsMu       sync.Mutex
+		flights         map[string]*flight
+		waiting         map[*stack.Stack[Info]]string
 	}
 )
 
-// Err returns nil if a Module was loaded or an error if a problem occurred.
-func (e Event) Err() error {
-	return e.err
-}
-
 // New constructs Scaffold(ing) to apply Mortar on Stone from Module(s).
 func New(mort Mortar, opt ...Option) *Scaffold {
 	s := &Scaffold{
-		mort:    mort,
-		modules: make(map[string]*moduleWrapper),
-		skip:    DefaultSkipper,
+		mort:     mort,
+		modules:  make(map[string]*moduleWrapper),
+		skip:     DefaultSkipper,
+		skipped:  make(map[string]Info),
+		unloaded: make(map[string]*moduleWrapper),
+		registry: registry,
+		workers:  1,
+		recover:  true,
+		timeouts: make(map[string]time.Duration),
+		retries:  make(map[string]RetryPolicy),
+		flights:  make(map[string]*flight),
+		waiting:  make(map[*stack.Stack[Info]]string),
 	}
 	for _, fn := range opt {
 		fn(s)
@@ -62,32 +79,300 @@ func New(mort Mortar, opt ...Option) *Scaffold {
 	return s
 }
 
-// Load loads Module(s) using a Context.
+// Load loads Module(s) using a Context. Loading stops at the first failure unless ContinueOnError is set. Panics in
+// Module.Provision are returned as a PanicError unless disabled by RecoverOption, in which case the PanicError is
+// re-raised once Scaffold(ing) has been cleaned up.
 func (s *Scaffold) Load(ctx context.Context, mod ...Module) error {
+	if _, ok := s.mort.(Unhooker); s.transactional && !ok {
+		return fmt.Errorf("scaffold failed to load, transactional %w", ErrUnhookUnsupported)
+	}
 	// register Module(s)
-	var registered []Info
+	var (
+		registered []Info
+		events     []Event
+	)
 	s.modulesMu.Lock()
 	for _, m := range mod {
 		info := m.Info()
 		if s.skip(info) {
+			s.skipped[info.String()] = info
+			events = append(events, Event{Info: info, Phase: PhaseSkipped})
 			continue
 		}
-		if w, ok := s.modules[info.String()]; !ok || !w.loaded {
-			s.modules[info.String()] = &moduleWrapper{
-				Module: m,
-			}
+		if w, ok := s.modules[info.String()]; !ok || !w.state.Loaded() {
+			s.modules[info.String()] = newModuleWrapper(m)
+			delete(s.unloaded, info.String())
 			registered = append(registered, info)
+			events = append(events, Event{Info: info, Phase: PhaseRegistered})
 		}
 	}
 	s.modulesMu.Unlock()
-	c := newContext(ctx, s)
+	for _, e := range events {
+		s.publish(e)
+	}
+	// plan registered Module(s)
+	mods, _ := s.planning()
+	order, err := plan(mods, registered)
+	var (
+		failed  []Info
+		planErr *PlanError
+	)
+	if s.continueOnError && errors.As(err, &planErr) {
+		// isolate unplannable Module(s) and plan the rest
+		failed = s.isolate(mods, planErr)
+		roots := make([]Info, 0, len(registered))
+		for _, info := range registered {
+			if _, ok := mods[info.String()]; ok {
+				roots = append(roots, info)
+			}
+		}
+		order, err = plan(mods, roots)
+	}
+	if err != nil {
+		return fmt.Errorf("scaffold failed to load, %w", err)
+	}
 	// load registered Module(s)
-	if err := c.Load(registered...); err != nil {
+	var loaded map[string]bool
+	if s.transactional {
+		loaded = s.loadedSet()
+	}
+	if s.workers > 1 {
+		err = s.parallel(ctx, order)
+	} else {
+		err = s.sequential(ctx, order)
+	}
+	if s.continueOnError {
+		err = s.failures(ctx, append(failed, order...))
+	}
+	if err != nil {
+		if s.transactional && !s.continueOnError {
+			if _err := s.rollbackSince(loaded); _err != nil {
+				err = errors.Join(err, _err)
+			}
+		}
+		var e *PanicError
+		if !s.recover && errors.As(err, &e) {
+			panic(e)
+		}
 		return fmt.Errorf("scaffold failed to load, %w", err)
 	}
 	return nil
 }
 
+// loadedSet lists loaded Module(s) by reference.
+func (s *Scaffold) loadedSet() map[string]bool {
+	s.modulesMu.RLock()
+	defer s.modulesMu.RUnlock()
+	loaded := make(map[string]bool, len(s.modules))
+	for ref, w := range s.modules {
+		loaded[ref] = w.state.Loaded()
+	}
+	return loaded
+}
+
+// rollbackSince rolls back Module(s) loaded since a loadedSet in reverse dependency order.
+func (s *Scaffold) rollbackSince(loaded map[string]bool) error {
+	var errs []error
+	order := s.order()
+	for i := len(order) - 1; i >= 0; i-- {
+		if !loaded[order[i].Info().String()] {
+			errs = append(errs, s.rollback(order[i]))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// rollback unhooks Stone hooked by a Module in reverse order and reverts it to registered, unless it is still
+// provisioning.
+func (s *Scaffold) rollback(w *moduleWrapper) error {
+	s.modulesMu.Lock()
+	stones := w.stones
+	w.stones, w.runtime = nil, 0
+	if w.state != StateProvisioning {
+		_ = w.transition(StateRegistered)
+	}
+	s.modulesMu.Unlock()
+	w.depsMu.Lock()
+	w.deps = nil
+	w.depsMu.Unlock()
+	if err := s.unhook(stones...); err != nil {
+		return fmt.Errorf("%s failed to rollback, %w", w.Info(), err)
+	}
+	return nil
+}
+
+// sequential loads planned Module(s) one at a time with a single Context.
+func (s *Scaffold) sequential(ctx context.Context, order []Info) error {
+	c := newContext(ctx, s)
+	for _, i := range order {
+		if err := c.Load(i); err != nil && !s.continueOnError {
+			return err
+		}
+	}
+	return nil
+}
+
+// failures joins the failures of planned Module(s), each as a LoadError naming the failed Module.
+func (s *Scaffold) failures(ctx context.Context, order []Info) error {
+	var errs []error
+	for _, i := range order {
+		w, ok := s.get(i)
+		if !ok {
+			continue
+		}
+		err := s.failure(w)
+		if err == nil {
+			continue
+		}
+		var e *LoadError
+		if errors.As(err, &e) && e.Info != i {
+			// failed because of a dependency
+			err = &LoadError{Info: i, Dependency: e.Info, Err: err}
+		}
+		errs = append(errs, err)
+	}
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// parallel loads planned Module(s) on a bounded worker pool, each with its own Context, once their static
+// dependencies have loaded. Shared dependencies are provisioned once by whichever worker reaches them first.
+func (s *Scaffold) parallel(ctx context.Context, order []Info) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+		sem  = make(chan struct{}, s.workers)
+		done = make(map[string]chan struct{}, len(order))
+	)
+	for _, i := range order {
+		done[i.String()] = make(chan struct{})
+	}
+	for _, i := range order {
+		wg.Add(1)
+		go func(i Info) {
+			defer wg.Done()
+			defer close(done[i.String()])
+			if mod, ok := s.get(i); ok {
+				if dependent, ok := mod.Module.(Dependent); ok {
+					for _, dep := range dependent.Dependencies() {
+						ch, ok := done[dep.String()]
+						if !ok {
+							continue
+						}
+						select {
+						case <-ch:
+						case <-ctx.Done():
+							return
+						}
+					}
+				}
+			}
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+			if _err := newContext(ctx, s).Load(i); _err != nil && !s.continueOnError {
+				once.Do(func() {
+					err = _err
+					cancel()
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err == nil {
+		err = ctx.Err()
+	}
+	return
+}
+
+// Start starts loaded Module(s) that implement Starter in dependency order.
+func (s *Scaffold) Start(ctx context.Context) error {
+	return s.start(ctx, s.order())
+}
+
+// start starts Module(s) that implement Starter in order.
+func (s *Scaffold) start(ctx context.Context, mods []*moduleWrapper) error {
+	for _, w := range mods {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("scaffold failed to start, %w", err)
+		}
+		starter, ok := w.Module.(Starter)
+		if !ok || !s.can(w, StateStarted) {
+			continue
+		}
+		start := time.Now()
+		err := starter.Start(ctx)
+		s.publish(Event{Info: w.Info(), Phase: PhaseStarted, Time: start, Duration: time.Since(start), err: err})
+		if err != nil {
+			s.fail(w, err)
+			return fmt.Errorf("scaffold failed to start %s, %w", w.Info(), err)
+		}
+		if err = s.transition(w, StateStarted); err != nil {
+			return fmt.Errorf("scaffold failed to start %s, %w", w.Info(), err)
+		}
+	}
+	return nil
+}
+
+// Shutdown stops loaded Module(s) that implement Stopper in reverse dependency order. Module(s) that cannot be
+// stopped before the Context deadline are reported alongside any other failures.
+func (s *Scaffold) Shutdown(ctx context.Context) error {
+	order := s.order()
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	if errs := s.stop(ctx, order); len(errs) > 0 {
+		return fmt.Errorf("scaffold failed to shutdown, %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// stop stops Module(s) that implement Stopper in order.
+func (s *Scaffold) stop(ctx context.Context, mods []*moduleWrapper) (errs []error) {
+	for _, w := range mods {
+		stopper, ok := w.Module.(Stopper)
+		if !ok || !s.can(w, StateStopped) {
+			continue
+		}
+		start := time.Now()
+		err := stopModule(ctx, stopper)
+		s.publish(Event{Info: w.Info(), Phase: PhaseStopped, Time: start, Duration: time.Since(start), err: err})
+		if err != nil {
+			s.fail(w, err)
+			errs = append(errs, fmt.Errorf("%s failed to stop, %w", w.Info(), err))
+			continue
+		}
+		if err = s.transition(w, StateStopped); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return
+}
+
+// stopModule calls Stopper.Stop without outliving the Context.
+func stopModule(ctx context.Context, stopper Stopper) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- stopper.Stop(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Stat returns the Load runtime for a Module.
 func (s *Scaffold) Stat(info Info) time.Duration {
 	s.modulesMu.RLock()
@@ -99,52 +384,117 @@ func (s *Scaffold) Stat(info Info) time.Duration {
 	return mod.runtime
 }
 
-// hook conveniently wraps Mortar.Hook.
-func (s *Scaffold) hook(stone ...Stone) error {
+// hook conveniently wraps ModuleHooker.HookModule, falling back to Mortar.Hook.
+func (s *Scaffold) hook(info Info, stone ...Stone) error {
+	if hooker, ok := s.mort.(ModuleHooker); ok {
+		return hooker.HookModule(info, stone...)
+	}
 	return s.mort.Hook(stone...)
 }
 
+// unhook conveniently wraps Unhooker.Unhook, unmounting Stone in reverse order. It is a no-op if Mortar does not
+// implement Unhooker.
+func (s *Scaffold) unhook(stone ...Stone) error {
+	unhooker, ok := s.mort.(Unhooker)
+	if !ok || len(stone) == 0 {
+		return nil
+	}
+	reversed := make([]Stone, 0, len(stone))
+	for i := len(stone) - 1; i >= 0; i-- {
+		reversed = append(reversed, stone[i])
+	}
+	return unhooker.Unhook(reversed...)
+}
+
+// record tracks Stone hooked by a Module.
+func (s *Scaffold) record(info Info, stone ...Stone) {
+	s.modulesMu.Lock()
+	defer s.modulesMu.Unlock()
+	if w, ok := s.modules[info.String()]; ok {
+		w.stones = append(w.stones, stone...)
+	}
+}
+
 // get returns a registered Module if it exists.
-func (s *Scaffold) get(info Info) (Module, bool, bool) {
+func (s *Scaffold) get(info Info) (*moduleWrapper, bool) {
 	s.modulesMu.RLock()
 	defer s.modulesMu.RUnlock()
 	mod, ok := s.modules[info.String()]
-	if !ok {
-		return nil, false, false
+	return mod, ok
+}
+
+// timeout returns the provisioning deadline budget for a Module, or zero for none.
+func (s *Scaffold) timeout(mod Module) time.Duration {
+	if d, ok := s.timeouts[mod.Info().String()]; ok {
+		return d
+	}
+	if timeouter, ok := mod.(Timeouter); ok {
+		return timeouter.Timeout()
 	}
-	return mod, true, mod.loaded
+	return 0
+}
+
+// fail records the last error of a Module, marking it as failed if it was provisioning.
+func (s *Scaffold) fail(w *moduleWrapper, err error) {
+	s.modulesMu.Lock()
+	defer s.modulesMu.Unlock()
+	w.err = err
+	if w.state == StateProvisioning {
+		_ = w.transition(StateFailed)
+	}
+}
+
+// failure returns why a Module failed to load, if it did.
+func (s *Scaffold) failure(mod *moduleWrapper) error {
+	s.modulesMu.RLock()
+	defer s.modulesMu.RUnlock()
+	if mod.state != StateFailed {
+		return nil
+	}
+	return mod.err
+}
+
+// isLoaded checks whether a Module has been loaded.
+func (s *Scaffold) isLoaded(mod *moduleWrapper) bool {
+	s.modulesMu.RLock()
+	defer s.modulesMu.RUnlock()
+	return mod.state.Loaded()
+}
+
+// provisioning marks a Module as provisioning, unless it has failed meanwhile.
+func (s *Scaffold) provisioning(mod *moduleWrapper) error {
+	s.modulesMu.Lock()
+	defer s.modulesMu.Unlock()
+	if mod.state == StateFailed {
+		return mod.err
+	}
+	return mod.transition(StateProvisioning)
 }
 
 // set updates a Module with provision metadata.
-func (s *Scaffold) set(info Info, start time.Time) {
+func (s *Scaffold) set(info Info, start time.Time) error {
 	s.modulesMu.Lock()
 	defer s.modulesMu.Unlock()
 	mod, ok := s.modules[info.String()]
-	if ok {
-		mod.loaded = true
-		mod.runtime = time.Since(start)
+	if !ok {
+		return nil
 	}
+	mod.runtime = time.Since(start)
+	return mod.transition(StateLoaded)
 }
 
 // depend appends dependencies by Info to Module.
-func (s *Scaffold) depend(mod Module, info ...Info) bool {
+func (s *Scaffold) depend(from Info, optional bool, info ...Info) bool {
 	s.modulesMu.RLock()
 	defer s.modulesMu.RUnlock()
-	w, ok := s.modules[mod.Info().String()]
+	w, ok := s.modules[from.String()]
 	if !ok {
 		return false
 	}
-	w.dependsOn(info...)
+	w.dependsOn(optional, info...)
 	return true
 }
 
-// publish is a publisher utility for Event(s).
-func (s *Scaffold) publish(e Event) {
-	if s.ch != nil {
-		s.ch <- e
-	}
-}
-
 // Append couples Scaffold(ing), thus merging Module stats.
 func (s *Scaffold) Append(scaffolding ...*Scaffold) {
 	s.modulesMu.Lock()
@@ -152,20 +502,40 @@ func (s *Scaffold) Append(scaffolding ...*Scaffold) {
 	for _, scaffold := range scaffolding {
 		scaffold.modulesMu.RLock()
 		for ref, mod := range scaffold.modules {
-			if w, ok := s.modules[ref]; !ok || !w.loaded {
+			if w, ok := s.modules[ref]; !ok || !w.state.Loaded() {
 				s.modules[ref] = mod
 			}
 		}
+		for ref, info := range scaffold.skipped {
+			s.skipped[ref] = info
+		}
 		scaffold.modulesMu.RUnlock()
 	}
 }
 
+// started checks whether a Module has been started.
+func (s *Scaffold) started(w *moduleWrapper) bool {
+	s.modulesMu.RLock()
+	defer s.modulesMu.RUnlock()
+	return w.state == StateStarted
+}
+
+// order lists loaded Module(s) in dependency order, such that dependencies precede their dependents.
+func (s *Scaffold) order() (mods []*moduleWrapper) {
+	for _, info := range s.Snapshot().TopoOrder() {
+		if w, ok := s.get(info); ok && s.isLoaded(w) {
+			mods = append(mods, w)
+		}
+	}
+	return
+}
+
 // loaded lists all registered Module(s) that have been loaded.
 func (s *Scaffold) loaded() (info []Info) {
 	s.modulesMu.RLock()
 	defer s.modulesMu.RUnlock()
 	for _, mod := range s.modules {
-		if mod.loaded {
+		if mod.state.Loaded() {
 			info = append(info, mod.Info())
 		}
 	}
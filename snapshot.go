@@ -0,0 +1,194 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+package mason
+
+import (
+	"sort"
+	"time"
+)
+
+type (
+	// Node is a Module in a Snapshot.
+	Node struct {
+		Info    Info
+		State   State
+		Loaded  bool
+		Skipped bool
+		Runtime time.Duration
+	}
+	// Snapshot is a point-in-time copy of the Scaffold(ing) dependency graph. Nodes and edges are sorted by Info.
+	Snapshot struct {
+		Nodes []Node
+		Edges []Dependency
+	}
+)
+
+// Snapshot copies the Module dependency graph, including skipped Module(s).
+func (s *Scaffold) Snapshot() *Snapshot {
+	snap := new(Snapshot)
+	s.modulesMu.RLock()
+	for _, w := range s.modules {
+		snap.Nodes = append(snap.Nodes, Node{Info: w.Info(), State: w.state, Loaded: w.state.Loaded(), Runtime: w.runtime})
+		snap.Edges = append(snap.Edges, w.listDeps()...)
+	}
+	for ref, info := range s.skipped {
+		if _, ok := s.modules[ref]; !ok {
+			snap.Nodes = append(snap.Nodes, Node{Info: info, State: StateSkipped, Skipped: true})
+		}
+	}
+	s.modulesMu.RUnlock()
+	sort.Slice(snap.Nodes, func(i, j int) bool {
+		return snap.Nodes[i].Info.String() < snap.Nodes[j].Info.String()
+	})
+	sort.Slice(snap.Edges, func(i, j int) bool {
+		if a, b := snap.Edges[i].From.String(), snap.Edges[j].From.String(); a != b {
+			return a < b
+		}
+		return snap.Edges[i].To.String() < snap.Edges[j].To.String()
+	})
+	return snap
+}
+
+// adjacency indexes edges by dependent and by dependency.
+func (snap *Snapshot) adjacency() (deps, dependents map[string][]Info) {
+	deps = make(map[string][]Info, len(snap.Nodes))
+	dependents = make(map[string][]Info, len(snap.Nodes))
+	for _, e := range snap.Edges {
+		deps[e.From.String()] = append(deps[e.From.String()], e.To)
+		dependents[e.To.String()] = append(dependents[e.To.String()], e.From)
+	}
+	return
+}
+
+// walk lists the Module(s) adjacent to info, or reachable from it if transitive, sorted by Info.
+func walk(adj map[string][]Info, info Info, transitive bool) (out []Info) {
+	seen := map[string]bool{info.String(): true}
+	queue := []Info{info}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, i := range adj[next.String()] {
+			if seen[i.String()] {
+				continue
+			}
+			seen[i.String()] = true
+			out = append(out, i)
+			if transitive {
+				queue = append(queue, i)
+			}
+		}
+	}
+	sortInfo(out)
+	return
+}
+
+// sortInfo sorts Info for determinism.
+func sortInfo(info []Info) {
+	sort.Slice(info, func(i, j int) bool {
+		return info[i].String() < info[j].String()
+	})
+}
+
+// DependenciesOf lists what a Module depends on, either directly or transitively.
+func (snap *Snapshot) DependenciesOf(info Info, transitive bool) []Info {
+	deps, _ := snap.adjacency()
+	return walk(deps, info, transitive)
+}
+
+// DependentsOf lists what depends on a Module, either directly or transitively. These are the Module(s) that
+// break if it is removed.
+func (snap *Snapshot) DependentsOf(info Info, transitive bool) []Info {
+	_, dependents := snap.adjacency()
+	return walk(dependents, info, transitive)
+}
+
+// Roots lists registered Module(s) that no other Module depends on.
+func (snap *Snapshot) Roots() (info []Info) {
+	_, dependents := snap.adjacency()
+	for _, n := range snap.Nodes {
+		if !n.Skipped && len(dependents[n.Info.String()]) == 0 {
+			info = append(info, n.Info)
+		}
+	}
+	return
+}
+
+// Leaves lists registered Module(s) without dependencies.
+func (snap *Snapshot) Leaves() (info []Info) {
+	deps, _ := snap.adjacency()
+	for _, n := range snap.Nodes {
+		if !n.Skipped && len(deps[n.Info.String()]) == 0 {
+			info = append(info, n.Info)
+		}
+	}
+	return
+}
+
+// Path returns the shortest dependency chain from one Module to another, inclusive, or nil if from does not
+// depend on to. Ties are broken by Info.
+func (snap *Snapshot) Path(from, to Info) []Info {
+	deps, _ := snap.adjacency()
+	prev := map[string]Info{from.String(): from}
+	queue := []Info{from}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		if next == to {
+			path := []Info{to}
+			for i := to; i != from; {
+				i = prev[i.String()]
+				path = append([]Info{i}, path...)
+			}
+			return path
+		}
+		for _, i := range deps[next.String()] {
+			if _, ok := prev[i.String()]; !ok {
+				prev[i.String()] = next
+				queue = append(queue, i)
+			}
+		}
+	}
+	return nil
+}
+
+// TopoOrder lists registered Module(s) such that dependencies precede their dependents. Ties are broken by Info.
+func (snap *Snapshot) TopoOrder() (info []Info) {
+	deps, _ := snap.adjacency()
+	visited := make(map[string]bool, len(snap.Nodes))
+	var visit func(i Info)
+	visit = func(i Info) {
+		if visited[i.String()] {
+			return
+		}
+		visited[i.String()] = true
+		for _, dep := range deps[i.String()] {
+			visit(dep)
+		}
+		info = append(info, i)
+	}
+	for _, n := range snap.Nodes {
+		if !n.Skipped {
+			visit(n.Info)
+		}
+	}
+	return
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+package mason
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// State(s) of a Module. StateUnknown is reported for Module(s) that were never registered.
+const (
+	StateUnknown State = iota
+	StateRegistered
+	StateSkipped
+	StateProvisioning
+	StateLoaded
+	StateFailed
+	StateStarted
+	StateStopped
+	StateUnloaded
+)
+
+var (
+	// transitions lists the valid State transitions.
+	transitions = map[State][]State{
+		StateRegistered:   {StateProvisioning, StateFailed, StateUnloaded},
+		StateProvisioning: {StateLoaded, StateFailed},
+		StateLoaded:       {StateStarted, StateStopped, StateRegistered, StateUnloaded},
+		StateStarted:      {StateStopped, StateUnloaded},
+		StateStopped:      {StateStarted, StateUnloaded},
+		StateFailed:       {StateUnloaded},
+	}
+	// states names State(s).
+	states = [...]string{
+		StateUnknown:      "unknown",
+		StateRegistered:   "registered",
+		StateSkipped:      "skipped",
+		StateProvisioning: "provisioning",
+		StateLoaded:       "loaded",
+		StateFailed:       "failed",
+		StateStarted:      "started",
+		StateStopped:      "stopped",
+		StateUnloaded:     "unloaded",
+	}
+)
+
+type (
+	// State is the lifecycle state of a Module.
+	State int
+	// Status is a snapshot of a Module lifecycle.
+	Status struct {
+		Info
+		State State
+		// Err is the last error of the Module, if any.
+		Err error
+		// Runtime is the provisioning runtime.
+		Runtime time.Duration
+		// Since is when each State was last entered.
+		Since map[State]time.Time
+		// Dependencies lists the recorded Module dependencies.
+		Dependencies []Dependency
+	}
+)
+
+// String implements fmt.Stringer.
+func (s State) String() string {
+	if s < 0 || int(s) >= len(states) {
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+	return states[s]
+}
+
+// Loaded checks whether a State implies a provisioned Module.
+func (s State) Loaded() bool {
+	return s == StateLoaded || s == StateStarted || s == StateStopped
+}
+
+// can checks whether a State transition is valid.
+func (s State) can(to State) bool {
+	for _, next := range transitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// State returns the State of a Module by Info.
+func (s *Scaffold) State(info Info) State {
+	s.modulesMu.RLock()
+	defer s.modulesMu.RUnlock()
+	ref := info.String()
+	if w, ok := s.modules[ref]; ok {
+		return w.state
+	}
+	if _, ok := s.skipped[ref]; ok {
+		return StateSkipped
+	}
+	if _, ok := s.unloaded[ref]; ok {
+		return StateUnloaded
+	}
+	return StateUnknown
+}
+
+// Modules returns the Status of every registered, skipped and unloaded Module, ordered by Info.
+func (s *Scaffold) Modules() (mods []Status) {
+	s.modulesMu.RLock()
+	for _, w := range s.modules {
+		mods = append(mods, w.status())
+	}
+	for ref, w := range s.unloaded {
+		if _, ok := s.modules[ref]; !ok {
+			mods = append(mods, w.status())
+		}
+	}
+	for ref, info := range s.skipped {
+		if _, ok := s.modules[ref]; !ok {
+			mods = append(mods, Status{Info: info, State: StateSkipped})
+		}
+	}
+	s.modulesMu.RUnlock()
+	sort.Slice(mods, func(i, j int) bool {
+		return mods[i].Info.String() < mods[j].Info.String()
+	})
+	return
+}
+
+// newModuleWrapper wraps a registered Module.
+func newModuleWrapper(mod Module) *moduleWrapper {
+	now := time.Now()
+	return &moduleWrapper{Module: mod, state: StateRegistered, since: map[State]time.Time{StateRegistered: now}}
+}
+
+// status copies the Status of a Module. The caller must hold modulesMu.
+func (w *moduleWrapper) status() Status {
+	st := Status{
+		Info:         w.Info(),
+		State:        w.state,
+		Err:          w.err,
+		Runtime:      w.runtime,
+		Since:        make(map[State]time.Time, len(w.since)),
+		Dependencies: w.listDeps(),
+	}
+	for state, t := range w.since {
+		st.Since[state] = t
+	}
+	return st
+}
+
+// transition moves a Module to a State, failing with ErrInvalidTransition unless it is valid. The caller must hold
+// modulesMu.
+func (w *moduleWrapper) transition(to State) error {
+	if !w.state.can(to) {
+		return fmt.Errorf("%w of %s from %s to %s", ErrInvalidTransition, w.Info(), w.state, to)
+	}
+	w.state = to
+	w.since[to] = time.Now()
+	return nil
+}
+
+// transition safely moves a Module to a State.
+func (s *Scaffold) transition(w *moduleWrapper, to State) error {
+	s.modulesMu.Lock()
+	defer s.modulesMu.Unlock()
+	return w.transition(to)
+}
+
+// can safely checks whether a Module can transition to a State.
+func (s *Scaffold) can(w *moduleWrapper, to State) bool {
+	s.modulesMu.RLock()
+	defer s.modulesMu.RUnlock()
+	return w.state.can(to)
+}
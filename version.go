@@ -0,0 +1,100 @@
+// Copyright (c) 2023 pedregon
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//
+// SPDX-License-Identifier: MIT
+
+package mason
+
+import (
+	"fmt"
+	"github.com/pedregon/mason/v2/internal/semver"
+	"sort"
+	"strings"
+)
+
+type (
+	// ConstraintError is returned when no registered Module satisfies a version constraint.
+	ConstraintError struct {
+		Name       string
+		Constraint string
+		Candidates []Info
+	}
+)
+
+// Error implements error.
+func (e *ConstraintError) Error() string {
+	versions := make([]string, 0, len(e.Candidates))
+	for _, candidate := range e.Candidates {
+		versions = append(versions, candidate.Version)
+	}
+	return fmt.Sprintf("%s: no version of %s satisfies '%s', candidates [%s]",
+		ErrMissingDependency, e.Name, e.Constraint, strings.Join(versions, ", "))
+}
+
+// Unwrap allows ConstraintError to satisfy errors.Is with ErrMissingDependency.
+func (e *ConstraintError) Unwrap() error {
+	return ErrMissingDependency
+}
+
+// resolve picks the highest registered version of a Module satisfying a semantic version constraint.
+func (s *Scaffold) resolve(name, constraint string) (Info, error) {
+	c, err := semver.ParseConstraint(constraint)
+	if err != nil {
+		return Info{}, fmt.Errorf("%w '%s'", err, constraint)
+	}
+	var (
+		best       Info
+		bestV      semver.Version
+		found      bool
+		candidates []Info
+	)
+	s.modulesMu.RLock()
+	for _, w := range s.modules {
+		info := w.Info()
+		if info.Name != name {
+			continue
+		}
+		candidates = append(candidates, info)
+		v, err := semver.Parse(info.Version)
+		if err != nil || !c.Check(v) {
+			continue
+		}
+		if !found || v.Compare(bestV) > 0 {
+			best, bestV, found = info, v, true
+		}
+	}
+	s.modulesMu.RUnlock()
+	if !found {
+		sort.Slice(candidates, func(i, j int) bool {
+			return lessVersion(candidates[i].Version, candidates[j].Version)
+		})
+		return Info{}, &ConstraintError{Name: name, Constraint: constraint, Candidates: candidates}
+	}
+	return best, nil
+}
+
+// lessVersion orders versions by semantic version precedence, falling back to lexical order.
+func lessVersion(a, b string) bool {
+	x, errA := semver.Parse(a)
+	y, errB := semver.Parse(b)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return x.Compare(y) < 0
+}